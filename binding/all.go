@@ -0,0 +1,234 @@
+package binding
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// Sources of the fields bound by All.
+const (
+	SourceURI    = "uri"
+	SourceQuery  = "query"
+	SourceForm   = "form"
+	SourceHeader = "header"
+	SourceCookie = "cookie"
+	SourceBody   = "body"
+)
+
+// SourceError is an error binding or validating a field, with the source the field is bound from.
+type SourceError struct {
+	// Source is one of the Source constants.
+	Source string
+	// Field is the key of the field in the source, or its namespace for validation errors.
+	// It is empty for errors of the whole body.
+	Field string
+	// Err is the underlying error, a *FieldError for validation errors.
+	Err error
+}
+
+func (e *SourceError) Error() string {
+	if e.Field == "" {
+		return e.Source + ": " + e.Err.Error()
+	}
+	return e.Source + " " + e.Field + ": " + e.Err.Error()
+}
+
+func (e *SourceError) Unwrap() error {
+	return e.Err
+}
+
+// SourceErrors aggregates the errors of All.
+type SourceErrors []*SourceError
+
+// Error concatenates all error elements in SourceErrors into a single string separated by \n.
+func (errs SourceErrors) Error() string {
+	s := make([]string, len(errs))
+	for i, err := range errs {
+		s[i] = err.Error()
+	}
+	return strings.Join(s, "\n")
+}
+
+// Unwrap returns the errors, so errors.Is and errors.As inspect every one of them.
+func (errs SourceErrors) Unwrap() []error {
+	s := make([]error, len(errs))
+	for i, err := range errs {
+		s[i] = err
+	}
+	return s
+}
+
+type allBinding struct{}
+
+func (allBinding) Name() string {
+	return "all"
+}
+
+// Bind fills obj from the path params (uri tag), the query (form tag), the headers (header
+// tag), the cookies (cookie tag) and the body, decoded by the binding Default selects. Form
+// bodies are bound with the form tag, their values override the query ones. Only the fields
+// carrying the tag of a source are bound from it. The sources are applied in the order query,
+// body, header, cookie and uri, so path params take precedence.
+//
+// obj is validated once, after all the sources were bound. The errors are returned as
+// SourceErrors; if any source fails, the validation is skipped.
+func (allBinding) Bind(req *http.Request, params map[string][]string, obj any) error {
+	var errs SourceErrors
+	collect := func(source string, err error) {
+		if err == nil {
+			return
+		}
+		var se *SourceError
+		if !errors.As(err, &se) {
+			se = &SourceError{Source: source, Err: err}
+		}
+		errs = append(errs, se)
+	}
+
+	query := req.URL.Query()
+	collect(SourceQuery, mappingByPtr(obj, taggedSource{formSource(query), "form", SourceQuery}, "form"))
+	collect(SourceQuery, mapNestedForm(obj, query, "form", true))
+	bodyForm, err := bindAllBody(req, obj)
+	collect(SourceBody, err)
+	collect(SourceHeader, mappingByPtr(obj, taggedSource{headerSource(req.Header), "header", SourceHeader}, "header"))
+	collect(SourceCookie, mappingByPtr(obj, taggedSource{newCookieSource(req.Cookies()), "cookie", SourceCookie}, "cookie"))
+	collect(SourceURI, mappingByPtr(obj, taggedSource{formSource(params), "uri", SourceURI}, "uri"))
+	if len(errs) > 0 {
+		return errs
+	}
+
+	err = validateCtx(req.Context(), obj)
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) {
+		return err
+	}
+	t := reflect.TypeOf(obj)
+	for _, fe := range verrs {
+		if fe.err == nil {
+			errs = append(errs, &SourceError{Source: SourceBody, Field: fe.Field, Err: fe})
+			continue
+		}
+		ns := fe.err.StructNamespace()
+		errs = append(errs, &SourceError{Source: fieldSource(t, ns, bodyForm), Field: ns, Err: fe})
+	}
+	return errs
+}
+
+// bindAllBody binds the request body to obj without validating it, and reports whether it
+// is a form.
+func bindAllBody(req *http.Request, obj any) (bool, error) {
+	if req.Method == http.MethodGet || req.Body == nil || req.Body == http.NoBody || req.ContentLength == 0 {
+		return false, nil
+	}
+
+	contentType := req.Header.Get("Content-Type")
+	switch b := Default(req.Method, contentType).(type) {
+	case formBinding, formPostBinding:
+		if err := transcodeForm(req); err != nil {
+			return true, err
+		}
+		if err := req.ParseForm(); err != nil {
+			return true, err
+		}
+		if err := mappingByPtr(obj, taggedSource{formSource(req.PostForm), "form", SourceForm}, "form"); err != nil {
+			return true, err
+		}
+		return true, mapNestedForm(obj, req.PostForm, "form", true)
+	case formMultipartBinding:
+		if err := req.ParseMultipartForm(defaultMemory); err != nil {
+			return true, err
+		}
+		if err := mappingByPtr(obj, taggedSource{(*multipartRequest)(req), "form", SourceForm}, "form"); err != nil {
+			return true, err
+		}
+		return true, mapNestedForm(obj, req.MultipartForm.Value, "form", true)
+	case bodyDecoder:
+		if err := b.decode(req.Body, obj); err != nil && !errors.Is(err, io.EOF) {
+			return false, err
+		}
+		return false, nil
+	default:
+		return false, fmt.Errorf("unsupported content type %q", parseMediaType(contentType))
+	}
+}
+
+// taggedSource only sets the fields carrying the tag, so fields are not bound from every
+// source by their names, and reports the errors with their source. The default values of
+// the tag are ignored by the form body, which shares them with the query.
+type taggedSource struct {
+	setter
+	tag    string
+	source string
+}
+
+func (s taggedSource) TrySet(value reflect.Value, field reflect.StructField, key string, opt setOptions) (bool, error) {
+	if _, ok := field.Tag.Lookup(s.tag); !ok {
+		return false, nil
+	}
+	if s.source == SourceForm {
+		opt = setOptions{}
+	}
+	isSet, err := s.setter.TrySet(value, field, key, opt)
+	if err != nil {
+		return false, &SourceError{Source: s.source, Field: key, Err: err}
+	}
+	return isSet, nil
+}
+
+// bodyTags are the struct tags of the body formats.
+var bodyTags = []string{"json", "xml", "yaml", "toml", "msgpack", "cbor", "codec", "protobuf"}
+
+// fieldSource returns the source of the field at the struct namespace ns of the validator,
+// e.g. "User.Address.Street" or "User.Items[0].Name". A field with a form tag is reported
+// from the body if the body is a form or it has a tag of the body formats, from the query
+// otherwise.
+func fieldSource(t reflect.Type, ns string, bodyForm bool) string {
+	names := strings.Split(ns, ".")
+	var field reflect.StructField
+	for _, name := range names[1:] {
+		if i := strings.IndexByte(name, '['); i >= 0 {
+			name = name[:i]
+		}
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return SourceBody
+		}
+		f, ok := t.FieldByName(name)
+		if !ok {
+			return SourceBody
+		}
+		field = f
+		t = f.Type
+		for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+			t = t.Elem()
+		}
+	}
+
+	for _, ts := range [...]struct{ tag, source string }{
+		{"uri", SourceURI},
+		{"header", SourceHeader},
+		{"cookie", SourceCookie},
+	} {
+		if _, ok := field.Tag.Lookup(ts.tag); ok {
+			return ts.source
+		}
+	}
+	if _, ok := field.Tag.Lookup("form"); !ok {
+		return SourceBody
+	}
+	if bodyForm {
+		return SourceForm
+	}
+	for _, tag := range bodyTags {
+		if _, ok := field.Tag.Lookup(tag); ok {
+			return SourceBody
+		}
+	}
+	return SourceQuery
+}
@@ -0,0 +1,143 @@
+package binding
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type allRequest struct {
+	ID      int    `uri:"id" binding:"required"`
+	Page    int    `form:"page,default=1"`
+	Token   string `header:"X-Token" binding:"required"`
+	Session string `cookie:"session"`
+	Name    string `json:"name" xml:"name" form:"name" binding:"required"`
+	Age     int    `json:"age" xml:"age" form:"age" binding:"gte=18"`
+}
+
+func allRequestWithBody(method, path, contentType, body string) *http.Request {
+	req := requestWithBody(method, path, body)
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("X-Token", "secret")
+	req.AddCookie(&http.Cookie{Name: "session", Value: "abc"})
+	return req
+}
+
+func TestBindingAllJSON(t *testing.T) {
+	req := allRequestWithBody(http.MethodPost, "/users/7?page=3", MIMEJSON, `{"name":"bob","age":20}`)
+
+	var obj allRequest
+	require.NoError(t, All.Bind(req, map[string][]string{"id": {"7"}}, &obj))
+	assert.Equal(t, allRequest{ID: 7, Page: 3, Token: "secret", Session: "abc", Name: "bob", Age: 20}, obj)
+	assert.Equal(t, "all", All.Name())
+}
+
+func TestBindingAllXML(t *testing.T) {
+	req := allRequestWithBody(http.MethodPut, "/users/7", MIMEXML, `<allRequest><name>bob</name><age>30</age></allRequest>`)
+
+	var obj allRequest
+	require.NoError(t, All.Bind(req, map[string][]string{"id": {"7"}}, &obj))
+	assert.Equal(t, 1, obj.Page)
+	assert.Equal(t, "bob", obj.Name)
+	assert.Equal(t, 30, obj.Age)
+}
+
+func TestBindingAllForm(t *testing.T) {
+	req := allRequestWithBody(http.MethodPost, "/users/7?name=query&page=2", MIMEPOSTForm, "name=form&age=18")
+
+	var obj allRequest
+	require.NoError(t, All.Bind(req, map[string][]string{"id": {"7"}}, &obj))
+	assert.Equal(t, "form", obj.Name)
+	assert.Equal(t, 18, obj.Age)
+	assert.Equal(t, 2, obj.Page)
+}
+
+func TestBindingAllCookieUnescaped(t *testing.T) {
+	req := allRequestWithBody(http.MethodPost, "/users/7", MIMEJSON, `{"name":"bob","age":20}`)
+	req.Header.Del("Cookie")
+	req.AddCookie(&http.Cookie{Name: "session", Value: url.QueryEscape("a b/c")})
+
+	var obj allRequest
+	require.NoError(t, All.Bind(req, map[string][]string{"id": {"7"}}, &obj))
+	assert.Equal(t, "a b/c", obj.Session)
+}
+
+func TestBindingAllOnlyTaggedFields(t *testing.T) {
+	// the fields are not bound from the query by their names
+	req := allRequestWithBody(http.MethodPost, "/users/7?Token=query&Session=query", MIMEJSON, `{"name":"bob","age":20}`)
+	req.Header.Del("X-Token")
+
+	var obj allRequest
+	err := All.Bind(req, map[string][]string{"id": {"7"}}, &obj)
+	require.Error(t, err)
+	assert.Equal(t, "", obj.Token)
+	assert.Equal(t, "abc", obj.Session)
+}
+
+func TestBindingAllValidationErrors(t *testing.T) {
+	req := allRequestWithBody(http.MethodPost, "/", MIMEJSON, `{"age":10}`)
+	req.Header.Del("X-Token")
+
+	var obj allRequest
+	err := All.Bind(req, nil, &obj)
+
+	var errs SourceErrors
+	require.ErrorAs(t, err, &errs)
+	require.Len(t, errs, 4)
+	assert.Equal(t, SourceURI, errs[0].Source)
+	assert.Equal(t, "allRequest.ID", errs[0].Field)
+	assert.Equal(t, SourceHeader, errs[1].Source)
+	assert.Equal(t, SourceBody, errs[2].Source)
+	assert.Equal(t, "allRequest.Name", errs[2].Field)
+	assert.Equal(t, SourceBody, errs[3].Source)
+
+	var fe validator.FieldError
+	require.ErrorAs(t, err, &fe)
+	assert.Equal(t, "required", fe.Tag())
+	assert.Contains(t, err.Error(), "header allRequest.Token:")
+}
+
+func TestBindingAllFormValidationSource(t *testing.T) {
+	req := allRequestWithBody(http.MethodPost, "/", MIMEPOSTForm, "age=18")
+
+	var obj allRequest
+	err := All.Bind(req, map[string][]string{"id": {"1"}}, &obj)
+
+	var errs SourceErrors
+	require.ErrorAs(t, err, &errs)
+	require.Len(t, errs, 1)
+	assert.Equal(t, SourceForm, errs[0].Source)
+}
+
+func TestBindingAllSourceErrors(t *testing.T) {
+	req := allRequestWithBody(http.MethodPost, "/?page=x", MIMEJSON, `{"name":`)
+
+	var obj allRequest
+	err := All.Bind(req, map[string][]string{"id": {"y"}}, &obj)
+
+	var errs SourceErrors
+	require.ErrorAs(t, err, &errs)
+	require.Len(t, errs, 3)
+	assert.Equal(t, SourceQuery, errs[0].Source)
+	assert.Equal(t, "page", errs[0].Field)
+	assert.Equal(t, SourceBody, errs[1].Source)
+	assert.Equal(t, SourceURI, errs[2].Source)
+	assert.Equal(t, "id", errs[2].Field)
+
+	var se *SourceError
+	assert.True(t, errors.As(err, &se))
+}
+
+func TestBindingAllNoBody(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "/?name=bob&age=20", nil)
+	req.Header.Set("X-Token", "secret")
+
+	var obj allRequest
+	require.NoError(t, All.Bind(req, map[string][]string{"id": {"1"}}, &obj))
+	assert.Equal(t, "bob", obj.Name)
+}
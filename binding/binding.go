@@ -1,10 +1,14 @@
 package binding
 
-import "net/http"
+import (
+	"io"
+	"net/http"
+)
 
 // Content-Type MIME of the most common data formats.
 const (
-	MIMEJSON              = "aplication/json"
+	MIMEJSON              = "application/json"
+	MIMENDJSON            = "application/x-ndjson"
 	MIMEHTML              = "text/html"
 	MIMEXML               = "application/xml"
 	MIMEXML2              = "text/xml"
@@ -12,8 +16,13 @@ const (
 	MIMEPOSTForm          = "application/x-www-form-urlencoded"
 	MIMEMultipartPOSTForm = "multipart/form-data"
 	MIMEPROTOBUF          = "application/x-protobuf"
+	MIMEMSGPACK           = "application/x-msgpack"
+	MIMEMSGPACK2          = "application/msgpack"
+	MIMECBOR              = "application/cbor"
 	MIMEYAML              = "application/x-yaml"
 	MIMETOML              = "application/toml"
+	MIMEMergePatch        = "application/merge-patch+json"
+	MIMEJSONPatch         = "application/json-patch+json"
 )
 
 // Binding describes the interface which needs to be implemented for binding the data present
@@ -37,6 +46,23 @@ type BindingUri interface {
 	BindUri(map[string][]string, any) error
 }
 
+// bodyDecoder is implemented by the bindings of a request body, decode does not validate obj.
+type bodyDecoder interface {
+	decode(r io.Reader, obj any) error
+}
+
+var (
+	_ bodyDecoder = jsonBinding{}
+	_ bodyDecoder = xmlBinding{}
+	_ bodyDecoder = yamlBinding{}
+	_ bodyDecoder = tomlBinding{}
+	_ bodyDecoder = protobufBinding{}
+	_ bodyDecoder = msgpackBinding{}
+	_ bodyDecoder = cborBinding{}
+	_ bodyDecoder = mergePatchBinding{}
+	_ bodyDecoder = jsonPatchBinding{}
+)
+
 // These implement the Binding interface and can be used to bind the data
 // present in the request to struct instances.
 var (
@@ -49,31 +75,24 @@ var (
 	ProtoBuf      = protobufBinding{}
 	YAML          = yamlBinding{}
 	Uri           = uriBinding{}
+	All           = allBinding{}
 	Header        = headerBinding{}
 	TOML          = tomlBinding{}
+	MsgPack       = msgpackBinding{}
+	CBOR          = cborBinding{}
+	CBORStrict    = cborBinding{strict: true}
+	MergePatch    = mergePatchBinding{}
+	JSONPatch     = jsonPatchBinding{}
 )
 
 // Default returns the appropriate Binding instance based on the HTTP method
-// and the content type.
+// and the content type, see Register. The content type may carry parameters.
 func Default(method, contentType string) Binding {
 	if method == http.MethodGet {
 		return Form
 	}
-
-	switch contentType {
-	case MIMEJSON:
-		return JSON
-	case MIMEXML, MIMEXML2:
-		return XML
-	case MIMEPROTOBUF:
-		return ProtoBuf
-	case MIMEYAML:
-		return YAML
-	case MIMEMultipartPOSTForm:
-		return FormMultipart
-	case MIMETOML:
-		return TOML
-	default: // case MIMEPOSTForm:
-		return Form
+	if b, ok := Lookup(contentType); ok {
+		return b
 	}
+	return Form
 }
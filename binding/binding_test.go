@@ -162,6 +162,12 @@ func TestBindingDefault(t *testing.T) {
 
 	assert.Equal(t, TOML, Default("POST", MIMETOML))
 	assert.Equal(t, TOML, Default("PUT", MIMETOML))
+
+	assert.Equal(t, MsgPack, Default("POST", MIMEMSGPACK))
+	assert.Equal(t, MsgPack, Default("PUT", MIMEMSGPACK2))
+
+	assert.Equal(t, CBOR, Default("POST", MIMECBOR))
+	assert.Equal(t, CBOR, Default("PUT", MIMECBOR))
 }
 
 func TestBindingJSONNilBody(t *testing.T) {
@@ -0,0 +1,67 @@
+package binding
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+var (
+	// cborDecMode decodes time values from tagged (tags 0 and 1) and untagged data items.
+	cborDecMode = mustDecMode(cbor.DecOptions{
+		TimeTag: cbor.DecTagOptional,
+	})
+
+	// cborStrictDecMode rejects duplicate map keys and indefinite lengths, both of which
+	// allow equal documents to be encoded differently.
+	cborStrictDecMode = mustDecMode(cbor.DecOptions{
+		TimeTag:     cbor.DecTagOptional,
+		DupMapKey:   cbor.DupMapKeyEnforcedAPF,
+		IndefLength: cbor.IndefLengthForbidden,
+	})
+)
+
+func mustDecMode(opts cbor.DecOptions) cbor.DecMode {
+	dm, err := opts.DecMode()
+	if err != nil {
+		panic(err)
+	}
+	return dm
+}
+
+// cborBinding binds CBOR (RFC 8949) bodies. The "cbor" struct tags are honoured, falling
+// back to the "json" ones. In strict mode, used by CBORStrict, duplicate map keys and
+// indefinite lengths are rejected.
+type cborBinding struct {
+	strict bool
+}
+
+func (cborBinding) Name() string {
+	return "cbor"
+}
+
+func (b cborBinding) Bind(req *http.Request, obj any) error {
+	return b.decodeCBOR(req.Context(), req.Body, obj)
+}
+
+func (b cborBinding) BindBody(body []byte, obj any) error {
+	return b.decodeCBOR(context.Background(), bytes.NewReader(body), obj)
+}
+
+func (b cborBinding) decode(r io.Reader, obj any) error {
+	dm := cborDecMode
+	if b.strict {
+		dm = cborStrictDecMode
+	}
+	return dm.NewDecoder(r).Decode(obj)
+}
+
+func (b cborBinding) decodeCBOR(ctx context.Context, r io.Reader, obj any) error {
+	if err := b.decode(r, obj); err != nil {
+		return err
+	}
+	return validateCtx(ctx, obj)
+}
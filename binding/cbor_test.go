@@ -0,0 +1,74 @@
+package binding
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCBORBindingBindBody(t *testing.T) {
+	body, err := cbor.Marshal(map[string]any{"foo": "FOO"})
+	require.NoError(t, err)
+
+	var s struct {
+		Foo string `cbor:"foo"`
+	}
+	require.NoError(t, cborBinding{}.BindBody(body, &s))
+	assert.Equal(t, "FOO", s.Foo)
+}
+
+func TestBindingCBOR(t *testing.T) {
+	body, err := cbor.Marshal(FooStruct{"bar"})
+	require.NoError(t, err)
+	req := requestWithBody(http.MethodPost, "/", string(body))
+	req.Header.Set("Content-Type", MIMECBOR)
+
+	var obj FooStruct
+	require.NoError(t, CBOR.Bind(req, &obj))
+	assert.Equal(t, "bar", obj.Foo)
+	assert.Equal(t, "cbor", CBOR.Name())
+
+	body, err = cbor.Marshal(FooStruct{})
+	require.NoError(t, err)
+	assert.Error(t, CBOR.BindBody(body, &obj))
+}
+
+func TestBindingCBORTime(t *testing.T) {
+	var obj struct {
+		At time.Time `cbor:"at"`
+	}
+	// {"at": 1(1700000000)}
+	body := []byte{0xa1, 0x62, 'a', 't', 0xc1, 0x1a, 0x65, 0x53, 0xf1, 0x00}
+	require.NoError(t, CBOR.BindBody(body, &obj))
+	assert.Equal(t, int64(1700000000), obj.At.Unix())
+
+	// {"at": 0("2023-11-14T22:13:20Z")}
+	body = append([]byte{0xa1, 0x62, 'a', 't', 0xc0, 0x74}, "2023-11-14T22:13:20Z"...)
+	require.NoError(t, CBOR.BindBody(body, &obj))
+	assert.Equal(t, int64(1700000000), obj.At.Unix())
+}
+
+func TestBindingCBORStrict(t *testing.T) {
+	var obj map[string]int
+
+	// {"a": 1, "a": 2}
+	duplicate := []byte{0xa2, 0x61, 'a', 0x01, 0x61, 'a', 0x02}
+	require.NoError(t, CBOR.BindBody(duplicate, &obj))
+	assert.Error(t, CBORStrict.BindBody(duplicate, &obj))
+
+	// {_ "a": 1}
+	indefinite := []byte{0xbf, 0x61, 'a', 0x01, 0xff}
+	require.NoError(t, CBOR.BindBody(indefinite, &obj))
+	assert.Equal(t, 1, obj["a"])
+	assert.Error(t, CBORStrict.BindBody(indefinite, &obj))
+}
+
+func TestBindingCBORFail(t *testing.T) {
+	req := requestWithBody(http.MethodPost, "/", "\xff")
+	var obj FooStruct
+	assert.Error(t, CBOR.Bind(req, &obj))
+}
@@ -0,0 +1,78 @@
+package binding
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// maxFormSize is the limit of http.Request.ParseForm on urlencoded bodies.
+const maxFormSize = 10 << 20
+
+func isUTF8(charset string) bool {
+	return charset == "" || charset == "utf-8" || charset == "utf8" || charset == "us-ascii"
+}
+
+// charsetReader returns a reader transcoding r from charset to UTF-8. Its signature is the
+// one of xml.Decoder.CharsetReader.
+func charsetReader(charset string, r io.Reader) (io.Reader, error) {
+	charset = strings.ToLower(charset)
+	if isUTF8(charset) {
+		return r, nil
+	}
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported charset %q", charset)
+	}
+	return enc.NewDecoder().Reader(r), nil
+}
+
+// transcodeForm converts the urlencoded body of req to UTF-8 if its Content-Type has another
+// charset, since ParseForm assumes UTF-8. The values are decoded before they are transcoded,
+// as the escaped bytes are in the charset.
+func transcodeForm(req *http.Request) error {
+	contentType := req.Header.Get("Content-Type")
+	charset := mediaTypeCharset(contentType)
+	if isUTF8(charset) || req.Body == nil || req.PostForm != nil || parseMediaType(contentType) != MIMEPOSTForm {
+		return nil
+	}
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return fmt.Errorf("unsupported charset %q", charset)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxFormSize+1))
+	if err != nil {
+		return err
+	}
+	if len(body) > maxFormSize {
+		return errors.New("http: POST too large")
+	}
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return err
+	}
+
+	dec := enc.NewDecoder()
+	form := make(url.Values, len(values))
+	for key, vs := range values {
+		if key, err = dec.String(key); err != nil {
+			return err
+		}
+		for _, v := range vs {
+			if v, err = dec.String(v); err != nil {
+				return err
+			}
+			form[key] = append(form[key], v)
+		}
+	}
+	encoded := form.Encode()
+	req.Body = io.NopCloser(strings.NewReader(encoded))
+	req.ContentLength = int64(len(encoded))
+	return nil
+}
@@ -0,0 +1,25 @@
+package binding
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+)
+
+type cookieSource map[string][]string
+
+var _ setter = cookieSource(nil)
+
+// newCookieSource returns the values of cookies unescaped, as Context.Cookie returns them.
+func newCookieSource(cookies []*http.Cookie) cookieSource {
+	cs := make(cookieSource, len(cookies))
+	for _, c := range cookies {
+		val, _ := url.QueryUnescape(c.Value)
+		cs[c.Name] = append(cs[c.Name], val)
+	}
+	return cs
+}
+
+func (cs cookieSource) TrySet(value reflect.Value, field reflect.StructField, tagValue string, opt setOptions) (bool, error) {
+	return setByForm(value, field, cs, tagValue, opt)
+}
@@ -14,6 +14,9 @@ func (formBinding) Name() string {
 }
 
 func (formBinding) Bind(req *http.Request, obj any) error {
+	if err := transcodeForm(req); err != nil {
+		return err
+	}
 	if err := req.ParseForm(); err != nil {
 		return err
 	}
@@ -23,7 +26,7 @@ func (formBinding) Bind(req *http.Request, obj any) error {
 	if err := mapForm(obj, req.Form); err != nil {
 		return err
 	}
-	return validate(obj)
+	return validateCtx(req.Context(), obj)
 }
 
 type formPostBinding struct{}
@@ -33,13 +36,16 @@ func (formPostBinding) Name() string {
 }
 
 func (formPostBinding) Bind(req *http.Request, obj any) error {
+	if err := transcodeForm(req); err != nil {
+		return err
+	}
 	if err := req.ParseForm(); err != nil {
 		return err
 	}
 	if err := mapForm(obj, req.PostForm); err != nil {
 		return err
 	}
-	return validate(obj)
+	return validateCtx(req.Context(), obj)
 }
 
 type formMultipartBinding struct{}
@@ -55,6 +61,9 @@ func (formMultipartBinding) Bind(req *http.Request, obj any) error {
 	if err := mappingByPtr(obj, (*multipartRequest)(req), "form"); err != nil {
 		return err
 	}
+	if err := mapNestedForm(obj, req.MultipartForm.Value, "form", false); err != nil {
+		return err
+	}
 
-	return validate(obj)
+	return validateCtx(req.Context(), obj)
 }
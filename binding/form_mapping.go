@@ -1,13 +1,15 @@
 package binding
 
 import (
+	"dawn/codec/json"
 	"dawn/optimize/bytesconv"
-	"encoding/json"
+	"encoding"
 	"errors"
 	"fmt"
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -21,6 +23,84 @@ var (
 	ErrConvertToMapString = errors.New("can not convert to map of strings")
 )
 
+// BindUnmarshaler is the interface implemented by types that can unmarshal a form, query,
+// header or uri value of themselves.
+type BindUnmarshaler interface {
+	// UnmarshalParam decodes and assigns a value from a form, query, header or uri value.
+	UnmarshalParam(param string) error
+}
+
+var (
+	bindUnmarshalerType = reflect.TypeOf((*BindUnmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	timeType            = reflect.TypeOf(time.Time{})
+
+	convertersMu sync.RWMutex
+	converters   = make(map[reflect.Type]func(string) (any, error))
+)
+
+// RegisterConverter registers fn to convert form, query, header and uri values to typ, e.g. for
+// types of other packages which implement neither BindUnmarshaler nor encoding.TextUnmarshaler.
+// The values returned by fn must be assignable to typ. Converters take precedence over the
+// methods of typ.
+func RegisterConverter(typ reflect.Type, fn func(value string) (any, error)) {
+	convertersMu.Lock()
+	defer convertersMu.Unlock()
+	converters[typ] = fn
+}
+
+func converterFor(typ reflect.Type) (func(string) (any, error), bool) {
+	convertersMu.RLock()
+	defer convertersMu.RUnlock()
+	fn, ok := converters[typ]
+	return fn, ok
+}
+
+// isCustomType reports whether the values of typ are set by a converter, a BindUnmarshaler or
+// an encoding.TextUnmarshaler. time.Time is excluded from the latter to honour the time tags.
+func isCustomType(typ reflect.Type) bool {
+	if _, ok := converterFor(typ); ok {
+		return true
+	}
+	ptr := reflect.PtrTo(typ)
+	return ptr.Implements(bindUnmarshalerType) || (typ != timeType && ptr.Implements(textUnmarshalerType))
+}
+
+// trySetCustom sets value with a converter, a BindUnmarshaler or an encoding.TextUnmarshaler.
+func trySetCustom(val string, value reflect.Value) (isSet bool, err error) {
+	typ := value.Type()
+	if fn, ok := converterFor(typ); ok {
+		v, err := fn(val)
+		if err != nil {
+			return true, err
+		}
+		rv := reflect.ValueOf(v)
+		if !rv.IsValid() {
+			value.Set(reflect.Zero(typ))
+			return true, nil
+		}
+		if !rv.Type().AssignableTo(typ) {
+			return true, fmt.Errorf("converter returned %s, not assignable to %s", rv.Type(), typ)
+		}
+		value.Set(rv)
+		return true, nil
+	}
+
+	if !value.CanAddr() {
+		return false, nil
+	}
+	switch u := value.Addr().Interface().(type) {
+	case BindUnmarshaler:
+		return true, u.UnmarshalParam(val)
+	case encoding.TextUnmarshaler:
+		if typ == timeType {
+			return false, nil
+		}
+		return true, u.UnmarshalText(bytesconv.StringToBytes(val))
+	}
+	return false, nil
+}
+
 func mapURI(ptr any, m map[string][]string) error {
 	return mapFormByTag(ptr, m, "uri")
 }
@@ -51,7 +131,10 @@ func mapFormByTag(ptr any, form map[string][]string, tag string) error {
 		return setFormMap(ptr, form)
 	}
 
-	return mappingByPtr(ptr, formSource(form), tag)
+	if err := mappingByPtr(ptr, formSource(form), tag); err != nil {
+		return err
+	}
+	return mapNestedForm(ptr, form, tag, false)
 }
 
 // setter tries to set value on a walking by fields of a struct
@@ -165,7 +248,12 @@ func setByForm(value reflect.Value, field reflect.StructField, form map[string][
 		return false, nil
 	}
 
-	switch value.Kind() {
+	kind := value.Kind()
+	if (kind == reflect.Slice || kind == reflect.Array) && isCustomType(value.Type()) {
+		kind = reflect.Invalid // a single value, e.g. net.IP or a UUID array
+	}
+
+	switch kind {
 	case reflect.Slice:
 		if !ok {
 			vs = []string{opt.defaultValue}
@@ -193,7 +281,16 @@ func setByForm(value reflect.Value, field reflect.StructField, form map[string][
 }
 
 func setWithProperType(val string, value reflect.Value, field reflect.StructField) error {
+	if ok, err := trySetCustom(val, value); ok {
+		return err
+	}
+
 	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		return setWithProperType(val, value.Elem(), field)
 	case reflect.Int:
 		return setIntField(val, 0, value)
 	case reflect.Int8:
@@ -231,9 +328,9 @@ func setWithProperType(val string, value reflect.Value, field reflect.StructFiel
 		case time.Time:
 			return setTimeField(val, field, value)
 		}
-		return json.Unmarshal(bytesconv.StringToBytes(val), value.Addr().Interface())
+		return json.API().Unmarshal(bytesconv.StringToBytes(val), value.Addr().Interface())
 	case reflect.Map:
-		return json.Unmarshal(bytesconv.StringToBytes(val), value.Addr().Interface())
+		return json.API().Unmarshal(bytesconv.StringToBytes(val), value.Addr().Interface())
 	default:
 		return errUnknownType
 	}
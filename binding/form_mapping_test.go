@@ -1,11 +1,16 @@
 package binding
 
 import (
+	"errors"
+	"net"
+	"net/netip"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMappingBaseTypes(t *testing.T) {
@@ -284,3 +289,120 @@ func TestMappingIgnoredCircularRef(t *testing.T) {
 	err := mappingByPtr(&s, formSource{}, "form")
 	assert.NoError(t, err)
 }
+
+type customUnmarshalParam struct {
+	Protocol string
+	Path     string
+}
+
+func (f *customUnmarshalParam) UnmarshalParam(param string) error {
+	protocol, path, ok := strings.Cut(param, ":")
+	if !ok {
+		return errors.New("invalid format")
+	}
+	f.Protocol, f.Path = protocol, path
+	return nil
+}
+
+func TestMappingBindUnmarshaler(t *testing.T) {
+	var s struct {
+		FileData  customUnmarshalParam    `form:"data"`
+		FileSlice []customUnmarshalParam  `form:"slice"`
+		FileArray [2]customUnmarshalParam `form:"array"`
+		FilePtr   *customUnmarshalParam   `form:"ptr"`
+	}
+	err := mappingByPtr(&s, formSource{
+		"data":  {"file:/foo/happiness"},
+		"slice": {"file:/foo/one", "ftp:/foo/two"},
+		"array": {"file:/foo/one", "ftp:/foo/two"},
+		"ptr":   {"http:/foo/ptr"},
+	}, "form")
+	require.NoError(t, err)
+
+	assert.Equal(t, customUnmarshalParam{"file", "/foo/happiness"}, s.FileData)
+	assert.Equal(t, []customUnmarshalParam{{"file", "/foo/one"}, {"ftp", "/foo/two"}}, s.FileSlice)
+	assert.Equal(t, [2]customUnmarshalParam{{"file", "/foo/one"}, {"ftp", "/foo/two"}}, s.FileArray)
+	assert.Equal(t, &customUnmarshalParam{"http", "/foo/ptr"}, s.FilePtr)
+
+	err = mappingByPtr(&s, formSource{"data": {"invalid"}}, "form")
+	assert.Error(t, err)
+}
+
+func TestMappingTextUnmarshaler(t *testing.T) {
+	var s struct {
+		Addr     netip.Addr    `form:"addr"`
+		Addrs    []netip.Addr  `form:"addrs"`
+		AddrPtrs []*netip.Addr `form:"addr_ptrs"`
+		IP       net.IP        `form:"ip"`
+		Time     time.Time     `form:"time" time_format:"2006-01-02"`
+	}
+	err := mappingByPtr(&s, formSource{
+		"addr":      {"192.168.0.1"},
+		"addrs":     {"10.0.0.1", "::1"},
+		"addr_ptrs": {"10.0.0.2"},
+		"ip":        {"127.0.0.1"},
+		"time":      {"2023-11-14"},
+	}, "form")
+	require.NoError(t, err)
+
+	assert.Equal(t, netip.MustParseAddr("192.168.0.1"), s.Addr)
+	assert.Equal(t, []netip.Addr{netip.MustParseAddr("10.0.0.1"), netip.MustParseAddr("::1")}, s.Addrs)
+	require.Len(t, s.AddrPtrs, 1)
+	assert.Equal(t, netip.MustParseAddr("10.0.0.2"), *s.AddrPtrs[0])
+	assert.True(t, net.ParseIP("127.0.0.1").Equal(s.IP))
+	assert.Equal(t, 14, s.Time.Day())
+
+	err = mappingByPtr(&s, formSource{"addr": {"wrong"}}, "form")
+	assert.Error(t, err)
+}
+
+type customID [4]byte
+
+func TestMappingConverter(t *testing.T) {
+	RegisterConverter(reflect.TypeOf(customID{}), func(value string) (any, error) {
+		if len(value) != 4 {
+			return nil, errors.New("invalid id")
+		}
+		var id customID
+		copy(id[:], value)
+		return id, nil
+	})
+	t.Cleanup(func() {
+		convertersMu.Lock()
+		delete(converters, reflect.TypeOf(customID{}))
+		convertersMu.Unlock()
+	})
+
+	var s struct {
+		ID    customID    `form:"id"`
+		IDs   []customID  `form:"ids"`
+		Array [2]customID `form:"array"`
+	}
+	err := mappingByPtr(&s, formSource{
+		"id":    {"abcd"},
+		"ids":   {"abcd", "efgh"},
+		"array": {"ijkl", "mnop"},
+	}, "form")
+	require.NoError(t, err)
+	assert.Equal(t, customID{'a', 'b', 'c', 'd'}, s.ID)
+	assert.Equal(t, []customID{{'a', 'b', 'c', 'd'}, {'e', 'f', 'g', 'h'}}, s.IDs)
+	assert.Equal(t, [2]customID{{'i', 'j', 'k', 'l'}, {'m', 'n', 'o', 'p'}}, s.Array)
+
+	err = mappingByPtr(&s, formSource{"id": {"abc"}}, "form")
+	assert.Error(t, err)
+
+	RegisterConverter(reflect.TypeOf(customID{}), func(value string) (any, error) {
+		return value, nil
+	})
+	err = mappingByPtr(&s, formSource{"id": {"abcd"}}, "form")
+	assert.Error(t, err)
+}
+
+func TestMappingHeaderTextUnmarshaler(t *testing.T) {
+	var s struct {
+		Addr netip.Addr `header:"X-Real-Ip"`
+	}
+	err := mapHeader(&s, map[string][]string{"X-Real-Ip": {"10.1.2.3"}})
+	require.NoError(t, err)
+	assert.Equal(t, netip.MustParseAddr("10.1.2.3"), s.Addr)
+}
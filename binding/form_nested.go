@@ -0,0 +1,270 @@
+package binding
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MaxNestedFormIndex caps the indexes of nested form keys such as "items[3][sku]", so a
+// request can not make the binding allocate large slices.
+var MaxNestedFormIndex = 1000
+
+// formNode is a node of the tree of nested form keys, "user[address][city]=X" and
+// "user.address.city=X" both give user -> address -> city = [X].
+type formNode struct {
+	values   []string
+	children map[string]*formNode
+}
+
+func (n *formNode) child(key string) *formNode {
+	if n.children == nil {
+		n.children = make(map[string]*formNode)
+	}
+	c, ok := n.children[key]
+	if !ok {
+		c = &formNode{}
+		n.children[key] = c
+	}
+	return c
+}
+
+// isNestedKey reports whether key uses the bracket or dot notation.
+func isNestedKey(key string) bool {
+	return strings.ContainsAny(key, "[.")
+}
+
+// splitNestedKey splits "a[b][0].c" into [a b 0 c], a trailing "[]" appends to its parent and
+// gives no segment. ok is false for malformed keys.
+func splitNestedKey(key string) (path []string, ok bool) {
+	i := strings.IndexAny(key, "[.")
+	if i <= 0 {
+		return nil, false
+	}
+	path = append(path, key[:i])
+	for rest := key[i:]; rest != ""; {
+		switch rest[0] {
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, false
+			}
+			if end == 1 {
+				if len(rest) != 2 {
+					return nil, false
+				}
+				return path, true
+			}
+			path = append(path, rest[1:end])
+			rest = rest[end+1:]
+		case '.':
+			end := strings.IndexAny(rest[1:], "[.") + 1
+			if end == 0 {
+				end = len(rest)
+			}
+			if end == 1 {
+				return nil, false
+			}
+			path = append(path, rest[1:end])
+			rest = rest[end:]
+		default:
+			return nil, false
+		}
+	}
+	return path, true
+}
+
+// parseNestedForm returns the tree of the nested keys of form, or nil if there are none.
+func parseNestedForm(form map[string][]string) *formNode {
+	var root *formNode
+	for key, values := range form {
+		if !isNestedKey(key) {
+			continue
+		}
+		path, ok := splitNestedKey(key)
+		if !ok {
+			continue
+		}
+		if root == nil {
+			root = &formNode{}
+		}
+		n := root
+		for _, segment := range path {
+			n = n.child(segment)
+		}
+		n.values = append(n.values, values...)
+	}
+	return root
+}
+
+// mapNestedForm binds the nested keys of form, e.g. "user[address][city]", "items[0][sku]",
+// "filter.status" or "meta[k]", to the nested structs, slices, arrays and maps of ptr. The
+// keys match the tag of the fields, or their names. If explicit is true, the fields of ptr
+// itself only match their tag.
+func mapNestedForm(ptr any, form map[string][]string, tag string, explicit bool) error {
+	root := parseNestedForm(form)
+	if root == nil {
+		return nil
+	}
+	value := reflect.ValueOf(ptr)
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+	return setNestedStruct(value, root, tag, explicit)
+}
+
+func setNestedStruct(value reflect.Value, node *formNode, tag string, explicit bool) error {
+	tValue := value.Type()
+	for i := 0; i < value.NumField(); i++ {
+		sf := tValue.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous { // unexported
+			continue
+		}
+		tagValue, tagged := sf.Tag.Lookup(tag)
+		name, _ := head(tagValue, ",")
+		if name == "-" {
+			continue
+		}
+
+		if sf.Anonymous && !tagged {
+			fv := value.Field(i)
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					if !fv.CanSet() {
+						continue
+					}
+					fv.Set(reflect.New(sf.Type.Elem()))
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				if err := setNestedStruct(fv, node, tag, explicit); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if explicit && !tagged {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+
+		child, ok := node.children[name]
+		if !ok {
+			continue
+		}
+		if err := setNested(value.Field(i), sf, child, tag, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setNested(value reflect.Value, field reflect.StructField, node *formNode, tag, key string) error {
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		return setNested(value.Elem(), field, node, tag, key)
+	}
+
+	if node.children == nil || isCustomType(value.Type()) || value.Type() == timeType {
+		if node.values == nil {
+			return nil
+		}
+		_, err := setByForm(value, field, map[string][]string{key: node.values}, key, setOptions{})
+		return err
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		return setNestedStruct(value, node, tag, false)
+	case reflect.Map:
+		return setNestedMap(value, field, node, tag, key)
+	case reflect.Slice:
+		return setNestedSlice(value, field, node, tag, key)
+	case reflect.Array:
+		return setNestedArray(value, field, node, tag, key)
+	}
+	return nil
+}
+
+func setNestedMap(value reflect.Value, field reflect.StructField, node *formNode, tag, key string) error {
+	if value.Type().Key().Kind() != reflect.String {
+		return fmt.Errorf("%s: map key must be a string, not %s", key, value.Type().Key())
+	}
+	if value.IsNil() {
+		value.Set(reflect.MakeMapWithSize(value.Type(), len(node.children)))
+	}
+	for k, child := range node.children {
+		elem := reflect.New(value.Type().Elem()).Elem()
+		if existing := value.MapIndex(reflect.ValueOf(k).Convert(value.Type().Key())); existing.IsValid() {
+			elem.Set(existing)
+		}
+		if err := setNested(elem, field, child, tag, key+"["+k+"]"); err != nil {
+			return err
+		}
+		value.SetMapIndex(reflect.ValueOf(k).Convert(value.Type().Key()), elem)
+	}
+	return nil
+}
+
+// nestedIndexes returns the sorted indexes of the children of node. The indexes must be in
+// decimal without sign or leading zeros, so no two keys such as "1" and "01" give the same one.
+func nestedIndexes(node *formNode, key string) ([]int, error) {
+	indexes := make([]int, 0, len(node.children))
+	for k := range node.children {
+		i, err := strconv.Atoi(k)
+		if err != nil || i < 0 || strconv.Itoa(i) != k {
+			return nil, fmt.Errorf("%s: invalid index %q", key, k)
+		}
+		if i > MaxNestedFormIndex {
+			return nil, fmt.Errorf("%s: index %d exceeds the maximum %d", key, i, MaxNestedFormIndex)
+		}
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	return indexes, nil
+}
+
+// setNestedSlice sets the elements in the order of their indexes, sparse indexes are
+// compacted, so "items[0]" and "items[5]" give two elements.
+func setNestedSlice(value reflect.Value, field reflect.StructField, node *formNode, tag, key string) error {
+	indexes, err := nestedIndexes(node, key)
+	if err != nil {
+		return err
+	}
+	slice := reflect.MakeSlice(value.Type(), len(indexes), len(indexes))
+	for i, index := range indexes {
+		k := strconv.Itoa(index)
+		if err := setNested(slice.Index(i), field, node.children[k], tag, key+"["+k+"]"); err != nil {
+			return err
+		}
+	}
+	value.Set(slice)
+	return nil
+}
+
+func setNestedArray(value reflect.Value, field reflect.StructField, node *formNode, tag, key string) error {
+	indexes, err := nestedIndexes(node, key)
+	if err != nil {
+		return err
+	}
+	for _, index := range indexes {
+		if index >= value.Len() {
+			return fmt.Errorf("%s: index %d out of range for %s", key, index, value.Type())
+		}
+		k := strconv.Itoa(index)
+		if err := setNested(value.Index(index), field, node.children[k], tag, key+"["+k+"]"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
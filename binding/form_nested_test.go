@@ -0,0 +1,172 @@
+package binding
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type nestedAddress struct {
+	City string `form:"city"`
+	Zip  string `form:"zip"`
+}
+
+type nestedItem struct {
+	SKU string `form:"sku"`
+	Qty int    `form:"qty"`
+}
+
+type nestedOrder struct {
+	Name string `form:"name"`
+	User struct {
+		Email   string        `form:"email"`
+		Address nestedAddress `form:"address"`
+	} `form:"user"`
+	Items  []nestedItem      `form:"items"`
+	Tags   []string          `form:"tags"`
+	Meta   map[string]string `form:"meta"`
+	Groups map[string][]int  `form:"groups"`
+	Filter *struct {
+		Status string    `form:"status"`
+		Since  time.Time `form:"since" time_format:"2006-01-02"`
+	} `form:"filter"`
+	Pair [2]nestedItem `form:"pair"`
+}
+
+func TestSplitNestedKey(t *testing.T) {
+	for _, tt := range []struct {
+		key  string
+		path []string
+		ok   bool
+	}{
+		{"user[address][city]", []string{"user", "address", "city"}, true},
+		{"items[0][sku]", []string{"items", "0", "sku"}, true},
+		{"filter.status", []string{"filter", "status"}, true},
+		{"items[0].sku", []string{"items", "0", "sku"}, true},
+		{"tags[]", []string{"tags"}, true},
+		{"meta[a.b]", []string{"meta", "a.b"}, true},
+		{"[x]", nil, false},
+		{"a[b", nil, false},
+		{"a[]x", nil, false},
+		{"a..b", nil, false},
+		{"a[b]x", nil, false},
+	} {
+		path, ok := splitNestedKey(tt.key)
+		assert.Equal(t, tt.ok, ok, tt.key)
+		assert.Equal(t, tt.path, path, tt.key)
+	}
+}
+
+func TestMappingNestedForm(t *testing.T) {
+	form, err := url.ParseQuery("name=order" +
+		"&user[email]=a@b.c&user[address][city]=Paris&user.address.zip=75001" +
+		"&items[0][sku]=A&items[0][qty]=1&items[1][sku]=B&items[1].qty=2" +
+		"&tags[]=x&tags[]=y" +
+		"&meta[k]=v&meta[other]=w" +
+		"&groups[a][]=1&groups[a][]=2" +
+		"&filter.status=open&filter.since=2023-11-14" +
+		"&pair[1][sku]=P")
+	require.NoError(t, err)
+
+	var obj nestedOrder
+	require.NoError(t, mapForm(&obj, form))
+
+	assert.Equal(t, "order", obj.Name)
+	assert.Equal(t, "a@b.c", obj.User.Email)
+	assert.Equal(t, nestedAddress{City: "Paris", Zip: "75001"}, obj.User.Address)
+	assert.Equal(t, []nestedItem{{"A", 1}, {"B", 2}}, obj.Items)
+	assert.Equal(t, []string{"x", "y"}, obj.Tags)
+	assert.Equal(t, map[string]string{"k": "v", "other": "w"}, obj.Meta)
+	assert.Equal(t, map[string][]int{"a": {1, 2}}, obj.Groups)
+	require.NotNil(t, obj.Filter)
+	assert.Equal(t, "open", obj.Filter.Status)
+	assert.Equal(t, 14, obj.Filter.Since.Day())
+	assert.Equal(t, [2]nestedItem{{}, {SKU: "P"}}, obj.Pair)
+}
+
+func TestMappingNestedFormSparseIndexes(t *testing.T) {
+	form := map[string][]string{
+		"items[7][sku]": {"C"},
+		"items[0][sku]": {"A"},
+		"items[3][sku]": {"B"},
+	}
+	var obj nestedOrder
+	require.NoError(t, mapForm(&obj, form))
+	assert.Equal(t, []nestedItem{{SKU: "A"}, {SKU: "B"}, {SKU: "C"}}, obj.Items)
+}
+
+func TestMappingNestedFormIndexErrors(t *testing.T) {
+	var obj nestedOrder
+
+	err := mapForm(&obj, map[string][]string{"items[100000][sku]": {"A"}})
+	assert.ErrorContains(t, err, "exceeds the maximum")
+
+	err = mapForm(&obj, map[string][]string{"items[x][sku]": {"A"}})
+	assert.ErrorContains(t, err, "invalid index")
+
+	err = mapForm(&obj, map[string][]string{"pair[2][sku]": {"A"}})
+	assert.ErrorContains(t, err, "out of range")
+
+	// the indexes must be canonical, so no two keys give the same element.
+	for _, form := range []map[string][]string{
+		{"items[01][sku]": {"A"}},
+		{"items[+1][sku]": {"A"}},
+		{"items[1][sku]": {"A"}, "items[01][sku]": {"B"}},
+		{"pair[00][sku]": {"A"}},
+	} {
+		obj = nestedOrder{}
+		err = mapForm(&obj, form)
+		assert.ErrorContains(t, err, "invalid index", form)
+		assert.Empty(t, obj.Items)
+	}
+
+	err = mapForm(&obj, map[string][]string{"items[0][qty]": {"many"}})
+	assert.Error(t, err)
+}
+
+func TestBindingFormNested(t *testing.T) {
+	req := requestWithBody(http.MethodPost, "/?user[email]=q@b.c", "user[address][city]=Berlin&items[0][sku]=A")
+	req.Header.Add("Content-Type", MIMEPOSTForm)
+
+	var obj nestedOrder
+	require.NoError(t, Form.Bind(req, &obj))
+	assert.Equal(t, "q@b.c", obj.User.Email)
+	assert.Equal(t, "Berlin", obj.User.Address.City)
+	assert.Equal(t, []nestedItem{{SKU: "A"}}, obj.Items)
+}
+
+func TestBindingQueryNested(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "/?filter[status]=closed&meta[a]=b", nil)
+
+	var obj nestedOrder
+	require.NoError(t, Query.Bind(req, &obj))
+	assert.Equal(t, "closed", obj.Filter.Status)
+	assert.Equal(t, map[string]string{"a": "b"}, obj.Meta)
+}
+
+func TestBindingQueryNestedInvalidIndex(t *testing.T) {
+	for _, query := range []string{"items[01][sku]=A", "items[1][sku]=A&items[01][sku]=B", "items[%2B1][sku]=A"} {
+		req, _ := http.NewRequest(http.MethodGet, "/?"+query, nil)
+		var obj nestedOrder
+		assert.ErrorContains(t, Query.Bind(req, &obj), "invalid index", query)
+	}
+}
+
+func TestBindingMultipartNested(t *testing.T) {
+	body := "--boundary\r\n" +
+		"Content-Disposition: form-data; name=\"items[0][sku]\"\r\n\r\nA\r\n" +
+		"--boundary\r\n" +
+		"Content-Disposition: form-data; name=\"user.address.city\"\r\n\r\nRome\r\n" +
+		"--boundary--\r\n"
+	req := requestWithBody(http.MethodPost, "/", body)
+	req.Header.Set("Content-Type", MIMEMultipartPOSTForm+"; boundary=boundary")
+
+	var obj nestedOrder
+	require.NoError(t, FormMultipart.Bind(req, &obj))
+	assert.Equal(t, []nestedItem{{SKU: "A"}}, obj.Items)
+	assert.Equal(t, "Rome", obj.User.Address.City)
+}
@@ -2,32 +2,120 @@ package binding
 
 import (
 	"bytes"
-	"encoding/json"
+	"context"
 	"errors"
 	"io"
 	"net/http"
+
+	"dawn/codec/json"
 )
 
-type jsonBinding struct{}
+// JSONOptions configures the decoding of the JSON bindings.
+type JSONOptions struct {
+	// DisallowUnknownFields rejects object keys which match no field of the destination struct.
+	DisallowUnknownFields bool
+	// UseNumber decodes numbers into an interface{} as json.Number instead of float64.
+	UseNumber bool
+	// DisallowTrailingData rejects anything but white space after the first JSON value.
+	DisallowTrailingData bool
+	// DisallowDuplicateKeys rejects objects with the same key more than once.
+	DisallowDuplicateKeys bool
+	// CaseSensitive matches object keys to field names exactly. The keys matching a field
+	// name only when ignoring case, which encoding/json accepts, are unknown fields: ignored,
+	// or rejected with DisallowUnknownFields.
+	CaseSensitive bool
+	// MaxDepth limits the nesting of objects and arrays, zero means no limit.
+	MaxDepth int
+	// MaxBytes limits the size of the body, zero means no limit.
+	MaxBytes int64
+}
+
+// DefaultJSONOptions are the options of the JSON binding. Use NewJSON for bindings with
+// other options, e.g. a strict one for a public API while internal endpoints stay lenient.
+var DefaultJSONOptions JSONOptions
+
+// NewJSON returns a JSON binding decoding with opts instead of DefaultJSONOptions:
+//
+//	strictJSON := binding.NewJSON(binding.JSONOptions{DisallowUnknownFields: true})
+//	err := c.ShouldBindWith(&obj, strictJSON)
+func NewJSON(opts JSONOptions) BindingBody {
+	return jsonBinding{opts: &opts}
+}
+
+// SetJSONCodec makes c the JSON codec of the bindings and of the renders, nil restores the
+// built-in one. Call it before serving requests:
+//
+//	binding.SetJSONCodec(sonicCodec{})
+func SetJSONCodec(c json.Core) {
+	json.Use(c)
+}
+
+type jsonBinding struct {
+	opts *JSONOptions
+}
 
 func (jsonBinding) Name() string {
 	return "json"
 }
 
-func (jsonBinding) Bind(req *http.Request, obj any) error {
+func (b jsonBinding) Bind(req *http.Request, obj any) error {
 	if req == nil || req.Body == nil {
 		return errors.New("invalid request")
 	}
-	return decodeJSON(req.Body, obj)
+	return b.decodeJSON(req.Context(), req.Body, obj)
+}
+
+func (b jsonBinding) BindBody(body []byte, obj any) error {
+	return b.decodeJSON(context.Background(), bytes.NewReader(body), obj)
 }
 
-func (jsonBinding) BindBody(body []byte, obj any) error {
-	return decodeJSON(bytes.NewReader(body), obj)
+func (b jsonBinding) options() *JSONOptions {
+	if b.opts != nil {
+		return b.opts
+	}
+	return &DefaultJSONOptions
 }
 
-func decodeJSON(r io.Reader, obj any) error {
-	if err := json.NewDecoder(r).Decode(obj); err != nil {
+func (b jsonBinding) decode(r io.Reader, obj any) error {
+	opts := b.options()
+	data, err := readJSONBody(r, opts.MaxBytes)
+	if err != nil {
 		return err
 	}
-	return validate(obj)
+	if opts.DisallowUnknownFields || opts.DisallowTrailingData || opts.DisallowDuplicateKeys || opts.CaseSensitive || opts.MaxDepth > 0 {
+		if data, err = scanJSON(data, obj, opts); err != nil {
+			return err
+		}
+	}
+
+	dec := json.API().NewDecoder(bytes.NewReader(data))
+	if opts.UseNumber {
+		dec.UseNumber()
+	}
+	if opts.DisallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+	return codecDecodeError(data, obj, dec.Decode(obj))
+}
+
+func (b jsonBinding) decodeJSON(ctx context.Context, r io.Reader, obj any) error {
+	if err := b.decode(r, obj); err != nil {
+		return err
+	}
+	return validateCtx(ctx, obj)
+}
+
+// readJSONBody reads r, failing with ErrJSONTooLarge beyond maxBytes if positive.
+func readJSONBody(r io.Reader, maxBytes int64) ([]byte, error) {
+	if maxBytes <= 0 {
+		return io.ReadAll(r)
+	}
+	data, err := io.ReadAll(io.LimitReader(r, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, &JSONError{Offset: maxBytes, Err: ErrJSONTooLarge}
+	}
+	return data, nil
 }
@@ -0,0 +1,275 @@
+package binding
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	codec "dawn/codec/json"
+)
+
+var (
+	// ErrJSONUnknownField is reported when DisallowUnknownFields rejects a key.
+	ErrJSONUnknownField = errors.New("unknown field")
+	// ErrJSONDuplicateKey is reported when DisallowDuplicateKeys rejects a key.
+	ErrJSONDuplicateKey = errors.New("duplicate key")
+	// ErrJSONTrailingData is reported when DisallowTrailingData rejects the body.
+	ErrJSONTrailingData = errors.New("trailing data after JSON value")
+	// ErrJSONTooDeep is reported when the body nests deeper than MaxDepth.
+	ErrJSONTooDeep = errors.New("maximum nesting depth exceeded")
+	// ErrJSONTooLarge is reported when the body is larger than MaxBytes.
+	ErrJSONTooLarge = errors.New("body too large")
+)
+
+// JSONError reports where the decoding of a JSON body failed. Err is one of the ErrJSON
+// errors, or the *SyntaxError or *UnmarshalTypeError of encoding/json or of the codec.
+type JSONError struct {
+	// Field is the path of the failing field, e.g. "items[2].sku", empty for the whole body.
+	Field string
+	// Offset is the byte offset in the body where the error was detected.
+	Offset int64
+	Err    error
+}
+
+func (e *JSONError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("json: %v (offset %d)", e.Err, e.Offset)
+	}
+	return fmt.Sprintf("json: field %q: %v (offset %d)", e.Field, e.Err, e.Offset)
+}
+
+func (e *JSONError) Unwrap() error {
+	return e.Err
+}
+
+// jsonDecodeError converts the errors of encoding/json and of the codec carrying an offset to
+// a *JSONError, other errors are returned as is.
+func jsonDecodeError(err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var codecSyntaxErr *codec.SyntaxError
+	var codecTypeErr *codec.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		return &JSONError{Offset: syntaxErr.Offset, Err: err}
+	case errors.As(err, &typeErr):
+		return &JSONError{Field: typeErr.Field, Offset: typeErr.Offset, Err: err}
+	case errors.As(err, &codecSyntaxErr):
+		return &JSONError{Offset: codecSyntaxErr.Offset, Err: err}
+	case errors.As(err, &codecTypeErr):
+		return &JSONError{Field: codecTypeErr.Field, Offset: codecTypeErr.Offset, Err: err}
+	}
+	return err
+}
+
+// codecErrors reports whether the codec has its own error types, whose offsets and fields
+// differ from those of encoding/json.
+var codecErrors = reflect.TypeOf(codec.SyntaxError{}) != reflect.TypeOf(json.SyntaxError{})
+
+// codecDecodeError converts the error of the codec decoding data into obj like jsonDecodeError.
+// The offsets and fields of the errors of another codec than encoding/json are those of
+// encoding/json, so the errors do not depend on the codec.
+func codecDecodeError(data []byte, obj any, err error) error {
+	var syntaxErr *codec.SyntaxError
+	var typeErr *codec.UnmarshalTypeError
+	if codecErrors && (errors.As(err, &syntaxErr) || errors.As(err, &typeErr)) {
+		if t := reflect.TypeOf(obj); t != nil && t.Kind() == reflect.Ptr {
+			stdErr := json.Unmarshal(data, reflect.New(t.Elem()).Interface())
+			var stdSyntaxErr *json.SyntaxError
+			var stdTypeErr *json.UnmarshalTypeError
+			if errors.As(stdErr, &stdSyntaxErr) || errors.As(stdErr, &stdTypeErr) {
+				return jsonDecodeError(stdErr)
+			}
+		}
+	}
+	return jsonDecodeError(err)
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// jsonFrame is an object or array being scanned.
+type jsonFrame struct {
+	object  bool
+	typ     reflect.Type // the Go type of the container, nil if untracked
+	path    string
+	keys    map[string]struct{}
+	wantKey bool
+	next    reflect.Type // the type of the value of the current key
+	nextKey string
+	index   int
+}
+
+// scanJSON checks data against the options decoding cannot enforce, following the Go type
+// of obj to find the unknown fields. It uses encoding/json whatever the codec, so the
+// errors are the same with every codec. It returns data to decode, where the keys matching
+// a field only when ignoring case are blanked if CaseSensitive is set, since decoding
+// matches them.
+func scanJSON(data []byte, obj any, opts *JSONOptions) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	decoded, copied := data, false
+
+	var stack []*jsonFrame
+	rootType := reflect.TypeOf(obj)
+	rootDone := false
+	for {
+		offset := dec.InputOffset()
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return decoded, nil
+		}
+		if rootDone {
+			// decoding ignores anything after the first value.
+			if opts.DisallowTrailingData {
+				return nil, &JSONError{Offset: offset, Err: ErrJSONTrailingData}
+			}
+			return decoded, nil
+		}
+		if err != nil {
+			return nil, jsonDecodeError(err)
+		}
+
+		var top *jsonFrame
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+		}
+
+		if delim, ok := tok.(json.Delim); ok && (delim == '}' || delim == ']') {
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				rootDone = true
+			} else {
+				stack[len(stack)-1].valueDone()
+			}
+			continue
+		}
+
+		if top != nil && top.object && top.wantKey {
+			key := tok.(string)
+			for offset < int64(len(data)) && strings.IndexByte(" \t\r\n,", data[offset]) >= 0 {
+				offset++
+			}
+			ignored, err := top.setKey(key, opts)
+			if err != nil {
+				return nil, &JSONError{Field: joinJSONPath(top.path, key), Offset: offset, Err: err}
+			}
+			if ignored {
+				if !copied {
+					decoded, copied = append([]byte(nil), data...), true
+				}
+				// an empty key matches no field, the padding keeps the offsets of the errors.
+				end := dec.InputOffset()
+				copy(decoded[offset:end], `""`)
+				for i := offset + 2; i < end; i++ {
+					decoded[i] = ' '
+				}
+			}
+			continue
+		}
+
+		typ, path := rootType, ""
+		if top != nil {
+			typ, path = top.valueType()
+		}
+		if delim, ok := tok.(json.Delim); ok {
+			if opts.MaxDepth > 0 && len(stack) >= opts.MaxDepth {
+				return nil, &JSONError{Field: path, Offset: offset, Err: ErrJSONTooDeep}
+			}
+			frame := &jsonFrame{object: delim == '{', typ: indirectJSONType(typ), path: path}
+			if frame.object {
+				frame.wantKey = true
+				frame.keys = make(map[string]struct{})
+			}
+			stack = append(stack, frame)
+			continue
+		}
+		if top == nil {
+			rootDone = true
+		} else {
+			top.valueDone()
+		}
+	}
+}
+
+// indirectJSONType returns the type decoding follows into, nil if it is not tracked because
+// the type decodes itself.
+func indirectJSONType(t reflect.Type) reflect.Type {
+	for t != nil {
+		if t.Kind() != reflect.Interface {
+			if ptr := reflect.PtrTo(t); ptr.Implements(jsonUnmarshalerType) || ptr.Implements(textUnmarshalerType) {
+				return nil
+			}
+		}
+		if t.Kind() != reflect.Ptr {
+			return t
+		}
+		t = t.Elem()
+	}
+	return nil
+}
+
+// setKey tracks the type of the value of key, ignored reports a key matching a field only
+// when ignoring case which CaseSensitive makes unknown.
+func (f *jsonFrame) setKey(key string, opts *JSONOptions) (ignored bool, err error) {
+	if opts.DisallowDuplicateKeys {
+		if _, ok := f.keys[key]; ok {
+			return false, ErrJSONDuplicateKey
+		}
+		f.keys[key] = struct{}{}
+	}
+	f.wantKey = false
+	f.nextKey = key
+	f.next = nil
+
+	if f.typ == nil {
+		return false, nil
+	}
+	switch f.typ.Kind() {
+	case reflect.Map:
+		f.next = f.typ.Elem()
+	case reflect.Struct:
+		field, folded := cachedTypeFields(f.typ, "json").lookup(key)
+		if field != nil && !(folded && opts.CaseSensitive) {
+			f.next = field.typ
+			return false, nil
+		}
+		ignored = field != nil
+		if opts.DisallowUnknownFields {
+			return false, ErrJSONUnknownField
+		}
+	}
+	return ignored, nil
+}
+
+// valueType returns the type and the path of the next value of the container.
+func (f *jsonFrame) valueType() (reflect.Type, string) {
+	if f.object {
+		return f.next, joinJSONPath(f.path, f.nextKey)
+	}
+	path := f.path + "[" + strconv.Itoa(f.index) + "]"
+	if f.typ != nil && (f.typ.Kind() == reflect.Slice || f.typ.Kind() == reflect.Array) {
+		return f.typ.Elem(), path
+	}
+	return nil, path
+}
+
+func (f *jsonFrame) valueDone() {
+	if f.object {
+		f.wantKey = true
+	} else {
+		f.index++
+	}
+}
+
+func joinJSONPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
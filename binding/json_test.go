@@ -1,8 +1,13 @@
 package binding
 
 import (
+	"encoding/json"
+	"io"
+	"net/http"
 	"testing"
 
+	codec "dawn/codec/json"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -24,3 +29,137 @@ func TestJSONBindingBindBodyMap(t *testing.T) {
 	assert.Equal(t, "FOO", s["foo"])
 	assert.Equal(t, "world", s["hello"])
 }
+
+type jsonStrictAddress struct {
+	City string `json:"city"`
+}
+
+type jsonStrictUser struct {
+	Name    string              `json:"name"`
+	Age     int                 `json:"age"`
+	Address jsonStrictAddress   `json:"address"`
+	Items   []jsonStrictAddress `json:"items"`
+	Extra   any                 `json:"extra"`
+	Meta    map[string]jsonStrictAddress
+}
+
+func TestJSONBindingOptions(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		opts   JSONOptions
+		body   string
+		err    error
+		field  string
+		offset int64
+	}{
+		{"lenient", JSONOptions{}, `{"name":"a","unknown":1,"NAME":"b"}`, nil, "", 0},
+		{"unknown field", JSONOptions{DisallowUnknownFields: true}, `{"name":"a","unknown":1}`, ErrJSONUnknownField, "unknown", 12},
+		{"nested unknown field", JSONOptions{DisallowUnknownFields: true}, `{"items":[{"city":"x"},{"town":"y"}]}`, ErrJSONUnknownField, "items[1].town", 24},
+		{"map unknown field", JSONOptions{DisallowUnknownFields: true}, `{"Meta":{"k":{"zip":1}}}`, ErrJSONUnknownField, "Meta.k.zip", 14},
+		{"any value", JSONOptions{DisallowUnknownFields: true}, `{"extra":{"anything":1}}`, nil, "", 0},
+		{"duplicate key", JSONOptions{DisallowDuplicateKeys: true}, `{"name":"a","age":1,"name":"b"}`, ErrJSONDuplicateKey, "name", 20},
+		{"nested duplicate key", JSONOptions{DisallowDuplicateKeys: true}, `{"address":{"city":"a","city":"b"}}`, ErrJSONDuplicateKey, "address.city", 23},
+		{"case insensitive", JSONOptions{DisallowUnknownFields: true}, `{"NAME":"a"}`, nil, "", 0},
+		{"case sensitive", JSONOptions{CaseSensitive: true}, `{"NAME":"a"}`, nil, "", 0},
+		{"case sensitive unknown", JSONOptions{CaseSensitive: true, DisallowUnknownFields: true}, `{"age":1,"NAME":"a"}`, ErrJSONUnknownField, "NAME", 9},
+		{"trailing data", JSONOptions{DisallowTrailingData: true}, `{"name":"a"} {}`, ErrJSONTrailingData, "", 12},
+		{"trailing garbage", JSONOptions{DisallowTrailingData: true}, `{"name":"a"}x`, ErrJSONTrailingData, "", 12},
+		{"trailing space", JSONOptions{DisallowTrailingData: true}, "{\"name\":\"a\"} \n", nil, "", 0},
+		{"max depth", JSONOptions{MaxDepth: 2}, `{"address":{"city":"a"}}`, nil, "", 0},
+		{"too deep", JSONOptions{MaxDepth: 2}, `{"extra":[[1]]}`, ErrJSONTooDeep, "extra[0]", 10},
+		{"max bytes", JSONOptions{MaxBytes: 12}, `{"name":"a"}`, nil, "", 0},
+		{"too large", JSONOptions{MaxBytes: 11}, `{"name":"a"}`, ErrJSONTooLarge, "", 11},
+	} {
+		var obj jsonStrictUser
+		err := NewJSON(tt.opts).BindBody([]byte(tt.body), &obj)
+		if tt.err == nil {
+			assert.NoError(t, err, tt.name)
+			continue
+		}
+		assert.ErrorIs(t, err, tt.err, tt.name)
+		var jsonErr *JSONError
+		if assert.ErrorAs(t, err, &jsonErr, tt.name) {
+			assert.Equal(t, tt.field, jsonErr.Field, tt.name)
+			assert.Equal(t, tt.offset, jsonErr.Offset, tt.name)
+		}
+	}
+}
+
+func TestJSONBindingCaseSensitive(t *testing.T) {
+	binding := NewJSON(JSONOptions{CaseSensitive: true})
+
+	var obj jsonStrictUser
+	require.NoError(t, binding.BindBody([]byte(`{"NAME":"a","name":"b","Age":"x","address":{"City":"c"}}`), &obj))
+	assert.Equal(t, "b", obj.Name)
+	assert.Equal(t, 0, obj.Age)
+	assert.Equal(t, "", obj.Address.City)
+
+	// the offsets of the decoding errors are those of the body.
+	err := binding.BindBody([]byte(`{"NAME":"a", "age":"x"}`), &obj)
+	var jsonErr *JSONError
+	require.ErrorAs(t, err, &jsonErr)
+	assert.Equal(t, "age", jsonErr.Field)
+	assert.Equal(t, int64(22), jsonErr.Offset)
+}
+
+func TestJSONBindingUseNumber(t *testing.T) {
+	var obj FooStructUseNumber
+	require.NoError(t, NewJSON(JSONOptions{UseNumber: true}).BindBody([]byte(`{"foo": 123}`), &obj))
+	assert.Equal(t, json.Number("123"), obj.Foo)
+
+	require.NoError(t, JSON.BindBody([]byte(`{"foo": 123}`), &obj))
+	assert.Equal(t, float64(123), obj.Foo)
+}
+
+func TestJSONBindingDefaultOptions(t *testing.T) {
+	DefaultJSONOptions = JSONOptions{DisallowUnknownFields: true}
+	defer func() { DefaultJSONOptions = JSONOptions{} }()
+
+	var obj FooStructDisallowUnknownFields
+	err := JSON.BindBody([]byte(`{"foo": "bar", "what": "this"}`), &obj)
+	assert.ErrorIs(t, err, ErrJSONUnknownField)
+
+	req := requestWithBody(http.MethodPost, "/", `{"foo": "bar", "what": "this"}`)
+	assert.ErrorIs(t, JSON.Bind(req, &obj), ErrJSONUnknownField)
+}
+
+func TestJSONBindingTypedDecodeErrors(t *testing.T) {
+	var obj jsonStrictUser
+	err := JSON.BindBody([]byte(`{"name":"a","address":{"city":1}}`), &obj)
+	var jsonErr *JSONError
+	require.ErrorAs(t, err, &jsonErr)
+	assert.Equal(t, "address.city", jsonErr.Field)
+	assert.Equal(t, int64(31), jsonErr.Offset)
+	var typeErr *json.UnmarshalTypeError
+	assert.ErrorAs(t, err, &typeErr)
+
+	err = JSON.BindBody([]byte(`{"name":}`), &obj)
+	require.ErrorAs(t, err, &jsonErr)
+	assert.Equal(t, int64(9), jsonErr.Offset)
+}
+
+type countingJSONCodec struct {
+	codec.Core
+	decoders int
+}
+
+func (c *countingJSONCodec) NewDecoder(r io.Reader) codec.Decoder {
+	c.decoders++
+	return c.Core.NewDecoder(r)
+}
+
+func TestJSONBindingCodec(t *testing.T) {
+	counting := &countingJSONCodec{Core: codec.Default()}
+	SetJSONCodec(counting)
+	defer SetJSONCodec(nil)
+
+	var obj FooStruct
+	require.NoError(t, JSON.BindBody([]byte(`{"foo": "bar"}`), &obj))
+	assert.Equal(t, "bar", obj.Foo)
+	require.NoError(t, NewJSON(JSONOptions{DisallowUnknownFields: true}).BindBody([]byte(`{"foo": "baz"}`), &obj))
+	assert.Equal(t, "baz", obj.Foo)
+	assert.Equal(t, 2, counting.decoders)
+
+	SetJSONCodec(nil)
+	assert.Equal(t, codec.Default(), codec.API())
+}
@@ -0,0 +1,37 @@
+package binding
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+
+	"dawn/codec/msgpack"
+
+	"github.com/ugorji/go/codec"
+)
+
+type msgpackBinding struct{}
+
+func (msgpackBinding) Name() string {
+	return "msgpack"
+}
+
+func (msgpackBinding) Bind(req *http.Request, obj any) error {
+	return decodeMsgPack(req.Context(), req.Body, obj)
+}
+
+func (msgpackBinding) BindBody(body []byte, obj any) error {
+	return decodeMsgPack(context.Background(), bytes.NewReader(body), obj)
+}
+
+func (msgpackBinding) decode(r io.Reader, obj any) error {
+	return codec.NewDecoder(r, msgpack.Handle).Decode(obj)
+}
+
+func decodeMsgPack(ctx context.Context, r io.Reader, obj any) error {
+	if err := (msgpackBinding{}).decode(r, obj); err != nil {
+		return err
+	}
+	return validateCtx(ctx, obj)
+}
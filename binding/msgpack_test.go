@@ -0,0 +1,66 @@
+package binding
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"dawn/codec/msgpack"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ugorji/go/codec"
+)
+
+func msgpackBody(t *testing.T, obj any) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, codec.NewEncoder(&buf, msgpack.Handle).Encode(obj))
+	return buf.Bytes()
+}
+
+func TestMsgpackBindingBindBody(t *testing.T) {
+	type teststruct struct {
+		Foo string `codec:"foo"`
+	}
+	body := msgpackBody(t, teststruct{"FOO"})
+	var encoded map[string]any
+	require.NoError(t, codec.NewDecoderBytes(body, msgpack.Handle).Decode(&encoded))
+	assert.Equal(t, map[string]any{"foo": "FOO"}, encoded)
+
+	var s teststruct
+	err := msgpackBinding{}.BindBody(body, &s)
+	require.NoError(t, err)
+	assert.Equal(t, "FOO", s.Foo)
+}
+
+func TestMsgpackBindingJSONTags(t *testing.T) {
+	body := msgpackBody(t, map[string]any{"foo": "bar", "count": 3})
+
+	var obj struct {
+		Foo   string `json:"foo"`
+		Count int    `json:"count,omitempty"`
+	}
+	require.NoError(t, MsgPack.BindBody(body, &obj))
+	assert.Equal(t, "bar", obj.Foo)
+	assert.Equal(t, 3, obj.Count)
+}
+
+func TestBindingMsgPack(t *testing.T) {
+	req := requestWithBody(http.MethodPost, "/", string(msgpackBody(t, FooStruct{"bar"})))
+	req.Header.Set("Content-Type", MIMEMSGPACK)
+
+	var obj FooStruct
+	require.NoError(t, MsgPack.Bind(req, &obj))
+	assert.Equal(t, "bar", obj.Foo)
+	assert.Equal(t, "msgpack", MsgPack.Name())
+
+	req = requestWithBody(http.MethodPost, "/", string(msgpackBody(t, FooStruct{})))
+	req.Header.Set("Content-Type", MIMEMSGPACK)
+	assert.Error(t, MsgPack.Bind(req, &obj))
+}
+
+func TestBindingMsgPackFail(t *testing.T) {
+	req := requestWithBody(http.MethodPost, "/", "\xc1")
+	var obj FooStruct
+	assert.Error(t, MsgPack.Bind(req, &obj))
+}
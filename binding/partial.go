@@ -0,0 +1,288 @@
+package binding
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"dawn/codec/json"
+)
+
+// FieldMask is the sorted set of the fields present in the input of a partial binding, by
+// their paths in the input, e.g. "name", "address", "address.city", "items" and
+// "items[0].sku". The paths of the parents of a field are part of the mask.
+type FieldMask []string
+
+// Has reports whether the field at path was present in the input.
+func (m FieldMask) Has(path string) bool {
+	i := sort.SearchStrings(m, path)
+	return i < len(m) && m[i] == path
+}
+
+// presence collects the fields present in the input, by their path in the input and their
+// namespace in the Go struct as reported by the validator, e.g. "Items[0].SKU".
+type presence struct {
+	paths      map[string]struct{}
+	namespaces map[string]struct{}
+}
+
+func newPresence() *presence {
+	return &presence{paths: make(map[string]struct{}), namespaces: make(map[string]struct{})}
+}
+
+func (p *presence) add(path, ns string) {
+	p.paths[path] = struct{}{}
+	p.namespaces[ns] = struct{}{}
+}
+
+func (p *presence) mask() FieldMask {
+	m := make(FieldMask, 0, len(p.paths))
+	for path := range p.paths {
+		m = append(m, path)
+	}
+	sort.Strings(m)
+	return m
+}
+
+// BindPartial binds req to obj with b, which must be JSON, Form, FormPost or Query, for a
+// partial update such as a PATCH request. Only the fields present in the input are set and
+// validated, their required rules are skipped, and the form defaults are not applied.
+// The returned FieldMask tells which fields were present, e.g. to update only their columns.
+func BindPartial(req *http.Request, obj any, b Binding) (FieldMask, error) {
+	if req == nil {
+		return nil, errors.New("invalid request")
+	}
+	p := newPresence()
+	t := reflect.TypeOf(obj)
+
+	switch b := b.(type) {
+	case jsonBinding:
+		if req.Body == nil {
+			return nil, errors.New("invalid request")
+		}
+		data, err := readJSONBody(req.Body, b.options().MaxBytes)
+		if err != nil {
+			return nil, err
+		}
+		if err := b.decode(bytes.NewReader(data), obj); err != nil {
+			return nil, err
+		}
+		var input any
+		if err := json.API().Unmarshal(data, &input); err != nil {
+			return nil, codecDecodeError(data, &input, err)
+		}
+		p.addJSON(input, t, "", "")
+	case formBinding, formPostBinding, queryBinding:
+		form, err := partialForm(req, b)
+		if err != nil {
+			return nil, err
+		}
+		if err := mappingByPtr(obj, partialSource{formSource(form)}, "form"); err != nil {
+			return nil, err
+		}
+		if err := mapNestedForm(obj, form, "form", false); err != nil {
+			return nil, err
+		}
+		p.addForm(form, t)
+	default:
+		return nil, fmt.Errorf("binding %s does not support partial binding", b.Name())
+	}
+
+	return p.mask(), p.validate(validateCtx(req.Context(), obj))
+}
+
+// partialForm returns the values b binds from.
+func partialForm(req *http.Request, b Binding) (map[string][]string, error) {
+	if _, ok := b.(queryBinding); ok {
+		return req.URL.Query(), nil
+	}
+	if err := transcodeForm(req); err != nil {
+		return nil, err
+	}
+	if err := req.ParseForm(); err != nil {
+		return nil, err
+	}
+	if _, ok := b.(formPostBinding); ok {
+		return req.PostForm, nil
+	}
+	if err := req.ParseMultipartForm(defaultMemory); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		return nil, err
+	}
+	return req.Form, nil
+}
+
+// partialSource ignores the default values of the form tags, which would overwrite the
+// fields absent from the input.
+type partialSource struct {
+	setter
+}
+
+func (s partialSource) TrySet(value reflect.Value, field reflect.StructField, key string, _ setOptions) (bool, error) {
+	return s.setter.TrySet(value, field, key, setOptions{})
+}
+
+// validate keeps the validation errors of the present fields, but their required rules.
+func (p *presence) validate(err error) error {
+	return p.filter(err, "")
+}
+
+// filter keeps the errors of err for the fields present under the namespace prefix, e.g.
+// "[2]" for the errors of the third element of a slice.
+func (p *presence) filter(err error, prefix string) error {
+	var sliceErrs SliceValidationError
+	if errors.As(err, &sliceErrs) {
+		kept := make(SliceValidationError, len(sliceErrs))
+		invalid := false
+		for i, e := range sliceErrs {
+			if e != nil {
+				kept[i] = p.filter(e, prefix+"["+strconv.Itoa(i)+"]")
+				invalid = invalid || kept[i] != nil
+			}
+		}
+		if !invalid {
+			return nil
+		}
+		return kept
+	}
+
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		return err
+	}
+	var kept ValidationErrors
+	for _, fe := range errs {
+		if fe.err == nil {
+			kept = append(kept, fe)
+			continue
+		}
+		if strings.HasPrefix(fe.Tag, "required") {
+			continue
+		}
+		ns := fe.err.StructNamespace()
+		// the namespace starts with the name of the validated struct.
+		if _, f, ok := strings.Cut(ns, "."); ok {
+			ns = f
+		}
+		if prefix != "" {
+			ns = prefix + "." + ns
+		}
+		if _, ok := p.namespaces[ns]; ok {
+			kept = append(kept, fe)
+		}
+	}
+	if kept == nil {
+		return nil
+	}
+	return kept
+}
+
+// addJSON adds the fields of the decoded JSON value v, bound to type t.
+func (p *presence) addJSON(v any, t reflect.Type, path, ns string) {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil {
+		return
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		for key, elem := range v {
+			switch t.Kind() {
+			case reflect.Struct:
+				names, ft, ok := structField(t, key, "json")
+				if !ok {
+					continue
+				}
+				p.addJSON(elem, ft, joinJSONPath(path, key), joinNamespace(ns, names))
+			case reflect.Map:
+				p.addJSON(elem, t.Elem(), joinJSONPath(path, key), ns+"["+key+"]")
+			}
+		}
+		if path != "" {
+			p.add(path, ns)
+		}
+	case []any:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for i, elem := range v {
+				index := "[" + strconv.Itoa(i) + "]"
+				p.addJSON(elem, t.Elem(), path+index, ns+index)
+			}
+		}
+		if path != "" {
+			p.add(path, ns)
+		}
+	default:
+		if path != "" {
+			p.add(path, ns)
+		}
+	}
+}
+
+// addForm adds the fields of the form keys, e.g. "name", "address[city]", "address.city" or
+// "items[0][sku]", bound to type t.
+func (p *presence) addForm(form map[string][]string, t reflect.Type) {
+	for key := range form {
+		segments := []string{key}
+		if isNestedKey(key) {
+			var ok bool
+			if segments, ok = splitNestedKey(key); !ok {
+				continue
+			}
+		}
+
+		typ, path, ns := t, "", ""
+		for _, segment := range segments {
+			for typ.Kind() == reflect.Ptr {
+				typ = typ.Elem()
+			}
+			switch typ.Kind() {
+			case reflect.Struct:
+				names, ft, ok := structField(typ, segment, "form")
+				if !ok {
+					typ = nil
+					break
+				}
+				path, ns, typ = joinJSONPath(path, segment), joinNamespace(ns, names), ft
+			case reflect.Slice, reflect.Array:
+				if _, err := strconv.Atoi(segment); err != nil {
+					typ = nil
+					break
+				}
+				path, ns, typ = path+"["+segment+"]", ns+"["+segment+"]", typ.Elem()
+			case reflect.Map:
+				path, ns, typ = joinJSONPath(path, segment), ns+"["+segment+"]", typ.Elem()
+			default:
+				typ = nil
+			}
+			if typ == nil {
+				break
+			}
+			p.add(path, ns)
+		}
+	}
+}
+
+// structField returns the Go names of the path to the field of struct type t named key by
+// tag, through the embedded structs, and the type of the field. The names of the json tag
+// also match regardless of case, like encoding/json does.
+func structField(t reflect.Type, key, tag string) ([]string, reflect.Type, bool) {
+	field, folded := cachedTypeFields(t, tag).lookup(key)
+	if field == nil || (folded && tag != "json") {
+		return nil, nil, false
+	}
+	return field.names, field.typ, true
+}
+
+func joinNamespace(ns string, names []string) string {
+	field := strings.Join(names, ".")
+	if ns == "" {
+		return field
+	}
+	return ns + "." + field
+}
@@ -0,0 +1,146 @@
+package binding
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type partialAddress struct {
+	Street string `json:"street" form:"street" binding:"required"`
+	City   string `json:"city" form:"city" binding:"required,min=2"`
+}
+
+type partialItem struct {
+	SKU      string `json:"sku" form:"sku" binding:"required,len=4"`
+	Quantity int    `json:"quantity" form:"quantity" binding:"min=1"`
+}
+
+type partialUser struct {
+	Name    string          `json:"name" form:"name" binding:"required"`
+	Email   string          `json:"email" form:"email" binding:"required,email"`
+	Age     int             `json:"age" form:"age,default=18" binding:"gte=18"`
+	Address *partialAddress `json:"address" form:"address"`
+	Items   []partialItem   `json:"items" form:"items" binding:"dive"`
+}
+
+func TestBindPartialJSON(t *testing.T) {
+	req := requestWithBody(http.MethodPatch, "/", `{"email":"a@example.com","address":{"city":"Paris"}}`)
+	var obj partialUser
+	mask, err := BindPartial(req, &obj, JSON)
+	require.NoError(t, err)
+
+	assert.Equal(t, FieldMask{"address", "address.city", "email"}, mask)
+	assert.True(t, mask.Has("address.city"))
+	assert.False(t, mask.Has("name"))
+	assert.Equal(t, "a@example.com", obj.Email)
+	assert.Equal(t, "Paris", obj.Address.City)
+}
+
+func TestBindPartialJSONInvalidPresentFields(t *testing.T) {
+	req := requestWithBody(http.MethodPatch, "/",
+		`{"email":"nope","age":12,"items":[{"sku":"ABCD","quantity":1},{"sku":"AB"}]}`)
+	var obj partialUser
+	mask, err := BindPartial(req, &obj, JSON)
+	require.Error(t, err)
+	assert.True(t, mask.Has("items[1].sku"))
+	assert.False(t, mask.Has("items[1].quantity"))
+
+	errs, ok := AsValidationErrors(err)
+	require.True(t, ok)
+	var fields []string
+	for _, fe := range errs {
+		fields = append(fields, fe.Field+":"+fe.Tag)
+	}
+	// items[1].quantity is absent, its min rule is skipped.
+	assert.ElementsMatch(t, []string{"email:email", "age:gte", "items[1].sku:len"}, fields)
+}
+
+func TestBindPartialJSONMap(t *testing.T) {
+	type settings struct {
+		Labels map[string]string `json:"labels" binding:"dive,max=3"`
+		Limit  int               `json:"limit" binding:"required,max=10"`
+	}
+	req := requestWithBody(http.MethodPatch, "/", `{"labels":{"env":"prod"}}`)
+	var obj settings
+	mask, err := BindPartial(req, &obj, JSON)
+	require.Error(t, err)
+	assert.Equal(t, FieldMask{"labels", "labels.env"}, mask)
+
+	errs, ok := AsValidationErrors(err)
+	require.True(t, ok)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "max", errs[0].Tag)
+}
+
+func TestBindPartialJSONSlice(t *testing.T) {
+	req := requestWithBody(http.MethodPatch, "/", `[{"email":"bad"},{"name":"Ann"},{"email":"bad2","age":12}]`)
+	var obj []partialUser
+	mask, err := BindPartial(req, &obj, JSON)
+	require.Error(t, err)
+	assert.True(t, mask.Has("[0].email"))
+	assert.True(t, mask.Has("[1].name"))
+
+	var sliceErrs SliceValidationError
+	require.ErrorAs(t, err, &sliceErrs)
+	require.Len(t, sliceErrs, 3)
+	// the second element only misses required fields.
+	assert.Nil(t, sliceErrs[1])
+
+	errs, ok := AsValidationErrors(err)
+	require.True(t, ok)
+	var fields []string
+	for _, fe := range errs {
+		fields = append(fields, fe.Field+":"+fe.Tag)
+	}
+	assert.ElementsMatch(t, []string{"[0].email:email", "[2].email:email", "[2].age:gte"}, fields)
+
+	req = requestWithBody(http.MethodPatch, "/", `[{"name":"Ann"}]`)
+	_, err = BindPartial(req, &obj, JSON)
+	assert.NoError(t, err)
+}
+
+func TestBindPartialForm(t *testing.T) {
+	req := requestWithBody(http.MethodPatch, "/", "name=Ann&address[city]=Rome")
+	req.Header.Set("Content-Type", MIMEPOSTForm)
+	var obj partialUser
+	obj.Age = 40
+	mask, err := BindPartial(req, &obj, Form)
+	require.NoError(t, err)
+
+	assert.Equal(t, FieldMask{"address", "address.city", "name"}, mask)
+	assert.Equal(t, "Ann", obj.Name)
+	assert.Equal(t, "Rome", obj.Address.City)
+	// the default of the absent age field is not applied.
+	assert.Equal(t, 40, obj.Age)
+}
+
+func TestBindPartialQuery(t *testing.T) {
+	req := requestWithBody(http.MethodPatch, "/?age=17&items[0][sku]=AB", "")
+	var obj partialUser
+	mask, err := BindPartial(req, &obj, Query)
+	require.Error(t, err)
+	assert.Equal(t, FieldMask{"age", "items", "items[0]", "items[0].sku"}, mask)
+
+	errs, ok := AsValidationErrors(err)
+	require.True(t, ok)
+	var tags []string
+	for _, fe := range errs {
+		tags = append(tags, fe.Tag)
+	}
+	assert.ElementsMatch(t, []string{"gte", "len"}, tags)
+}
+
+func TestBindPartialUnsupported(t *testing.T) {
+	req := requestWithBody(http.MethodPatch, "/", "<user/>")
+	var obj partialUser
+	_, err := BindPartial(req, &obj, XML)
+	assert.EqualError(t, err, "binding xml does not support partial binding")
+
+	for _, b := range []Binding{JSON, Form, Query} {
+		_, err = BindPartial(nil, &obj, b)
+		assert.EqualError(t, err, "invalid request")
+	}
+}
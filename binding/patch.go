@@ -0,0 +1,616 @@
+package binding
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	codec "dawn/codec/json"
+)
+
+var (
+	// ErrPatchInvalid is reported for a JSON Patch operation which is malformed, e.g. with an
+	// unknown op or without its value.
+	ErrPatchInvalid = errors.New("invalid operation")
+	// ErrPatchPathNotFound is reported when the location of an operation does not exist.
+	ErrPatchPathNotFound = errors.New("path not found")
+	// ErrPatchTestFailed is reported when the value of a test operation does not match.
+	ErrPatchTestFailed = errors.New("test failed")
+)
+
+// PatchError reports the JSON Patch operation which failed, no operation is applied then.
+type PatchError struct {
+	// Index is the index of the operation in the patch.
+	Index int
+	Op    string
+	// Path is the JSON Pointer of the operation, e.g. "/items/2/sku".
+	Path string
+	Err  error
+}
+
+func (e *PatchError) Error() string {
+	return fmt.Sprintf("json patch: operation %d: %s %q: %v", e.Index, e.Op, e.Path, e.Err)
+}
+
+func (e *PatchError) Unwrap() error {
+	return e.Err
+}
+
+// patchFunc applies patch to the decoded JSON document doc.
+type patchFunc func(doc any, patch []byte) (any, error)
+
+// ApplyMergePatch applies the JSON Merge Patch (RFC 7396) patch to the JSON document doc.
+func ApplyMergePatch(doc, patch []byte) ([]byte, error) {
+	return applyDocument(doc, patch, mergePatch)
+}
+
+// ApplyJSONPatch applies the JSON Patch (RFC 6902) patch to the JSON document doc. The patch
+// is atomic: if an operation fails, including a test one, the error is a *PatchError and no
+// operation is applied.
+func ApplyJSONPatch(doc, patch []byte) ([]byte, error) {
+	return applyDocument(doc, patch, jsonPatch)
+}
+
+func applyDocument(doc, patch []byte, apply patchFunc) ([]byte, error) {
+	v, err := decodeJSONValue(doc)
+	if err != nil {
+		return nil, err
+	}
+	if v, err = apply(v, patch); err != nil {
+		return nil, err
+	}
+	return encodeJSONValue(v)
+}
+
+type mergePatchBinding struct{}
+
+func (mergePatchBinding) Name() string {
+	return "merge-patch"
+}
+
+func (mergePatchBinding) Bind(req *http.Request, obj any) error {
+	if req == nil || req.Body == nil {
+		return errors.New("invalid request")
+	}
+	return decodePatch(req.Context(), req.Body, obj, mergePatch)
+}
+
+func (mergePatchBinding) BindBody(body []byte, obj any) error {
+	return decodePatch(context.Background(), bytes.NewReader(body), obj, mergePatch)
+}
+
+func (mergePatchBinding) decode(r io.Reader, obj any) error {
+	return patchValue(r, obj, mergePatch, nil)
+}
+
+type jsonPatchBinding struct{}
+
+func (jsonPatchBinding) Name() string {
+	return "json-patch"
+}
+
+func (jsonPatchBinding) Bind(req *http.Request, obj any) error {
+	if req == nil || req.Body == nil {
+		return errors.New("invalid request")
+	}
+	return decodePatch(req.Context(), req.Body, obj, jsonPatch)
+}
+
+func (jsonPatchBinding) BindBody(body []byte, obj any) error {
+	return decodePatch(context.Background(), bytes.NewReader(body), obj, jsonPatch)
+}
+
+func (jsonPatchBinding) decode(r io.Reader, obj any) error {
+	return patchValue(r, obj, jsonPatch, nil)
+}
+
+func decodePatch(ctx context.Context, r io.Reader, obj any, apply patchFunc) error {
+	return patchValue(r, obj, apply, func(patched any) error {
+		return validateCtx(ctx, patched)
+	})
+}
+
+// patchValue applies the patch read from r to the JSON document of obj, the current value to
+// patch, and decodes the result into obj once validate, if not nil, accepts it. The document
+// includes the fields omitted by omitempty, the fields removed by the patch are reset to their
+// zero values and the fields hidden from JSON are kept. obj is unchanged if the patch fails.
+func patchValue(r io.Reader, obj any, apply patchFunc, validate func(patched any) error) error {
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("patch target must be a non-nil pointer")
+	}
+	patch, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	data, err := codec.API().Marshal(obj)
+	if err != nil {
+		return err
+	}
+	doc, err := decodeJSONValue(data)
+	if err != nil {
+		return err
+	}
+	if err = addOmittedFields(doc, rv.Elem()); err != nil {
+		return err
+	}
+	if doc, err = apply(doc, patch); err != nil {
+		return err
+	}
+	if data, err = encodeJSONValue(doc); err != nil {
+		return err
+	}
+
+	patched := reflect.New(rv.Elem().Type())
+	patched.Elem().Set(rv.Elem())
+	resetJSONFields(patched.Elem())
+	if err := codec.API().Unmarshal(data, patched.Interface()); err != nil {
+		return codecDecodeError(data, patched.Interface(), err)
+	}
+	if validate != nil {
+		if err := validate(patched.Interface()); err != nil {
+			return err
+		}
+	}
+	rv.Elem().Set(patched.Elem())
+	return nil
+}
+
+// addOmittedFields adds the fields of v omitted from its JSON document doc by omitempty, with
+// their zero values, so the operations of a patch find them like the other fields.
+func addOmittedFields(doc any, v reflect.Value) error {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if indirectJSONType(v.Type()) == nil {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		if object, ok := doc.(map[string]any); ok {
+			return addOmittedStructFields(object, v)
+		}
+	case reflect.Slice, reflect.Array:
+		if array, ok := doc.([]any); ok && len(array) == v.Len() {
+			for i := range array {
+				if err := addOmittedFields(array[i], v.Index(i)); err != nil {
+					return err
+				}
+			}
+		}
+	case reflect.Map:
+		if object, ok := doc.(map[string]any); ok && v.Type().Key().Kind() == reflect.String {
+			iter := v.MapRange()
+			for iter.Next() {
+				if value, ok := object[iter.Key().String()]; ok {
+					if err := addOmittedFields(value, iter.Value()); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func addOmittedStructFields(object map[string]any, v reflect.Value) error {
+	for _, field := range cachedTypeFields(v.Type(), "json").list {
+		fv, err := v.FieldByIndexErr(field.index)
+		if err != nil {
+			// the field is promoted through a nil pointer.
+			continue
+		}
+		value, ok := object[field.name]
+		if !ok && field.omitEmpty {
+			data, err := codec.API().Marshal(fv.Interface())
+			if err != nil {
+				return err
+			}
+			if value, err = decodeJSONValue(data); err != nil {
+				return err
+			}
+			object[field.name] = value
+		}
+		if err := addOmittedFields(value, fv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resetJSONFields sets the fields of v which are encoded in JSON to their zero values.
+func resetJSONFields(v reflect.Value) {
+	if v.Kind() != reflect.Struct || indirectJSONType(v.Type()) == nil {
+		if v.CanSet() {
+			v.Set(reflect.Zero(v.Type()))
+		}
+		return
+	}
+	for i := 0; i < v.NumField(); i++ {
+		if v.Type().Field(i).Tag.Get("json") != "-" {
+			resetJSONFields(v.Field(i))
+		}
+	}
+}
+
+// decodeJSONValue decodes the JSON value of data, keeping the numbers as json.Number.
+func decodeJSONValue(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, jsonDecodeError(err)
+	}
+	offset := dec.InputOffset()
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, &JSONError{Offset: offset, Err: ErrJSONTrailingData}
+	}
+	return v, nil
+}
+
+func encodeJSONValue(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func mergePatch(doc any, patch []byte) (any, error) {
+	p, err := decodeJSONValue(patch)
+	if err != nil {
+		return nil, err
+	}
+	return mergeValue(doc, p), nil
+}
+
+// mergeValue merges patch into target as RFC 7396 defines it: the members of an object patch
+// are merged recursively, null removing them, and any other patch replaces target.
+func mergeValue(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = make(map[string]any, len(p))
+	}
+	for key, value := range p {
+		if value == nil {
+			delete(t, key)
+			continue
+		}
+		t[key] = mergeValue(t[key], value)
+	}
+	return t
+}
+
+// patchOperation is an operation of a JSON Patch. Value is nil when the member is absent and
+// "null" when it is null.
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+func jsonPatch(doc any, patch []byte) (any, error) {
+	var ops []patchOperation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, jsonDecodeError(err)
+	}
+	for i, op := range ops {
+		var err error
+		if doc, err = op.apply(doc); err != nil {
+			path := ""
+			if op.Path != nil {
+				path = *op.Path
+			}
+			return nil, &PatchError{Index: i, Op: op.Op, Path: path, Err: err}
+		}
+	}
+	return doc, nil
+}
+
+func (op patchOperation) apply(doc any) (any, error) {
+	if op.Path == nil {
+		return nil, fmt.Errorf("%w: missing path", ErrPatchInvalid)
+	}
+	path, err := parsePointer(*op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace":
+		value, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, value, op.Op == "replace")
+	case "remove":
+		doc, _, err := removeValue(doc, path)
+		return doc, err
+	case "move", "copy":
+		from, err := op.from()
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "copy" {
+			value, err := getValue(doc, from)
+			if err != nil {
+				return nil, err
+			}
+			return addValue(doc, path, copyValue(value), false)
+		}
+		if len(from) < len(path) && isPointerPrefix(from, path) {
+			return nil, fmt.Errorf("%w: cannot move a value into itself", ErrPatchInvalid)
+		}
+		doc, value, err := removeValue(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, value, false)
+	case "test":
+		value, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		current, err := getValue(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !equalValue(current, value) {
+			return nil, ErrPatchTestFailed
+		}
+		return doc, nil
+	}
+	return nil, fmt.Errorf("%w: unknown op %q", ErrPatchInvalid, op.Op)
+}
+
+func (op patchOperation) value() (any, error) {
+	if op.Value == nil {
+		return nil, fmt.Errorf("%w: missing value", ErrPatchInvalid)
+	}
+	return decodeJSONValue(op.Value)
+}
+
+func (op patchOperation) from() ([]string, error) {
+	if op.From == nil {
+		return nil, fmt.Errorf("%w: missing from", ErrPatchInvalid)
+	}
+	return parsePointer(*op.From)
+}
+
+var pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+// parsePointer returns the reference tokens of the JSON Pointer (RFC 6901) pointer, none for
+// the whole document.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("%w: invalid JSON pointer %q", ErrPatchInvalid, pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = pointerUnescaper.Replace(token)
+	}
+	return tokens, nil
+}
+
+func isPointerPrefix(prefix, tokens []string) bool {
+	for i := range prefix {
+		if prefix[i] != tokens[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// arrayIndex returns the index token refers to in an array of length n, up to n included if
+// end is true.
+func arrayIndex(token string, n int, end bool) (int, error) {
+	if end && token == "-" {
+		return n, nil
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || token[0] == '+' || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("%w: invalid array index %q", ErrPatchPathNotFound, token)
+	}
+	if i > n || (i == n && !end) {
+		return 0, fmt.Errorf("%w: array index %d out of range", ErrPatchPathNotFound, i)
+	}
+	return i, nil
+}
+
+func getValue(doc any, path []string) (any, error) {
+	for _, token := range path {
+		switch d := doc.(type) {
+		case map[string]any:
+			v, ok := d[token]
+			if !ok {
+				return nil, ErrPatchPathNotFound
+			}
+			doc = v
+		case []any:
+			i, err := arrayIndex(token, len(d), false)
+			if err != nil {
+				return nil, err
+			}
+			doc = d[i]
+		default:
+			return nil, ErrPatchPathNotFound
+		}
+	}
+	return doc, nil
+}
+
+// addValue adds value at path, or replaces the existing value if replace is true, and returns
+// the updated document.
+func addValue(doc any, path []string, value any, replace bool) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	token, rest := path[0], path[1:]
+
+	switch d := doc.(type) {
+	case map[string]any:
+		child, ok := d[token]
+		if len(rest) == 0 {
+			if replace && !ok {
+				return nil, ErrPatchPathNotFound
+			}
+			d[token] = value
+			return d, nil
+		}
+		if !ok {
+			return nil, ErrPatchPathNotFound
+		}
+		child, err := addValue(child, rest, value, replace)
+		if err != nil {
+			return nil, err
+		}
+		d[token] = child
+		return d, nil
+	case []any:
+		i, err := arrayIndex(token, len(d), len(rest) == 0 && !replace)
+		if err != nil {
+			return nil, err
+		}
+		if len(rest) == 0 {
+			if replace {
+				d[i] = value
+				return d, nil
+			}
+			d = append(d, nil)
+			copy(d[i+1:], d[i:])
+			d[i] = value
+			return d, nil
+		}
+		child, err := addValue(d[i], rest, value, replace)
+		if err != nil {
+			return nil, err
+		}
+		d[i] = child
+		return d, nil
+	}
+	return nil, ErrPatchPathNotFound
+}
+
+// removeValue removes the value at path and returns the updated document and the value.
+func removeValue(doc any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, nil, fmt.Errorf("%w: cannot remove the whole document", ErrPatchInvalid)
+	}
+	token, rest := path[0], path[1:]
+
+	switch d := doc.(type) {
+	case map[string]any:
+		child, ok := d[token]
+		if !ok {
+			return nil, nil, ErrPatchPathNotFound
+		}
+		if len(rest) == 0 {
+			delete(d, token)
+			return d, child, nil
+		}
+		child, removed, err := removeValue(child, rest)
+		if err != nil {
+			return nil, nil, err
+		}
+		d[token] = child
+		return d, removed, nil
+	case []any:
+		i, err := arrayIndex(token, len(d), false)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(rest) == 0 {
+			removed := d[i]
+			return append(d[:i], d[i+1:]...), removed, nil
+		}
+		child, removed, err := removeValue(d[i], rest)
+		if err != nil {
+			return nil, nil, err
+		}
+		d[i] = child
+		return d, removed, nil
+	}
+	return nil, nil, ErrPatchPathNotFound
+}
+
+func copyValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for key, value := range v {
+			m[key] = copyValue(value)
+		}
+		return m
+	case []any:
+		s := make([]any, len(v))
+		for i, value := range v {
+			s[i] = copyValue(value)
+		}
+		return s
+	}
+	return v
+}
+
+// equalValue reports whether the JSON values a and b are equal, numbers by their values.
+func equalValue(a, b any) bool {
+	switch a := a.(type) {
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for key, value := range a {
+			other, ok := b[key]
+			if !ok || !equalValue(value, other) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		b, ok := b.([]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equalValue(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		if a == b {
+			return true
+		}
+		// the numbers are compared exactly, large integers such as IDs do not fit a float64.
+		x, okA := new(big.Rat).SetString(a.String())
+		y, okB := new(big.Rat).SetString(b.String())
+		return okA && okB && x.Cmp(y) == 0
+	}
+	return a == b
+}
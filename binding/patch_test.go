@@ -0,0 +1,253 @@
+package binding
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type patchAddress struct {
+	City string `json:"city"`
+	Zip  string `json:"zip,omitempty"`
+}
+
+type patchUser struct {
+	ID       int               `json:"-"`
+	Name     string            `json:"name" binding:"required"`
+	Age      int               `json:"age" binding:"gte=0"`
+	Nickname *string           `json:"nickname"`
+	Address  patchAddress      `json:"address"`
+	Tags     []string          `json:"tags"`
+	Labels   map[string]string `json:"labels"`
+}
+
+func TestBindingDefaultPatch(t *testing.T) {
+	assert.Equal(t, MergePatch, Default(http.MethodPatch, MIMEMergePatch))
+	assert.Equal(t, JSONPatch, Default(http.MethodPatch, "application/json-patch+json; charset=utf-8"))
+	assert.Equal(t, "merge-patch", MergePatch.Name())
+	assert.Equal(t, "json-patch", JSONPatch.Name())
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	// the examples of RFC 7396, appendix A.
+	tests := []struct {
+		doc, patch, result string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tt := range tests {
+		result, err := ApplyMergePatch([]byte(tt.doc), []byte(tt.patch))
+		require.NoError(t, err, tt.patch)
+		assert.JSONEq(t, tt.result, string(result), tt.patch)
+	}
+
+	_, err := ApplyMergePatch([]byte(`{}`), []byte(`{"a":`))
+	assert.Error(t, err)
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	doc := `{"foo":"bar","list":[1,2,3],"nested":{"a~b":{"c/d":1}}}`
+	tests := []struct {
+		patch, result string
+	}{
+		{`[{"op":"add","path":"/baz","value":"qux"}]`,
+			`{"foo":"bar","baz":"qux","list":[1,2,3],"nested":{"a~b":{"c/d":1}}}`},
+		{`[{"op":"add","path":"/list/1","value":9},{"op":"add","path":"/list/-","value":4}]`,
+			`{"foo":"bar","list":[1,9,2,3,4],"nested":{"a~b":{"c/d":1}}}`},
+		{`[{"op":"remove","path":"/list/0"},{"op":"remove","path":"/nested/a~0b/c~1d"}]`,
+			`{"foo":"bar","list":[2,3],"nested":{"a~b":{}}}`},
+		{`[{"op":"replace","path":"/foo","value":null}]`,
+			`{"foo":null,"list":[1,2,3],"nested":{"a~b":{"c/d":1}}}`},
+		{`[{"op":"move","from":"/foo","path":"/nested/foo"}]`,
+			`{"list":[1,2,3],"nested":{"a~b":{"c/d":1},"foo":"bar"}}`},
+		{`[{"op":"copy","from":"/list","path":"/copy"},{"op":"add","path":"/copy/0","value":0}]`,
+			`{"foo":"bar","list":[1,2,3],"copy":[0,1,2,3],"nested":{"a~b":{"c/d":1}}}`},
+		{`[{"op":"test","path":"/list","value":[1,2.0,3e0]},{"op":"test","path":"/nested/a~0b","value":{"c/d":1}}]`,
+			doc},
+		{`[{"op":"replace","path":"","value":{"a":1}}]`, `{"a":1}`},
+	}
+	for _, tt := range tests {
+		result, err := ApplyJSONPatch([]byte(doc), []byte(tt.patch))
+		require.NoError(t, err, tt.patch)
+		assert.JSONEq(t, tt.result, string(result), tt.patch)
+	}
+}
+
+func TestApplyJSONPatchErrors(t *testing.T) {
+	doc := `{"foo":"bar","list":[1,2,3],"id":9007199254740993}`
+	tests := []struct {
+		patch string
+		index int
+		path  string
+		err   error
+	}{
+		{`[{"op":"test","path":"/foo","value":"baz"}]`, 0, "/foo", ErrPatchTestFailed},
+		{`[{"op":"test","path":"/id","value":9007199254740993},{"op":"test","path":"/id","value":9007199254740992}]`,
+			1, "/id", ErrPatchTestFailed},
+		{`[{"op":"add","path":"/a","value":1},{"op":"remove","path":"/missing"}]`, 1, "/missing", ErrPatchPathNotFound},
+		{`[{"op":"replace","path":"/missing","value":1}]`, 0, "/missing", ErrPatchPathNotFound},
+		{`[{"op":"add","path":"/list/4","value":1}]`, 0, "/list/4", ErrPatchPathNotFound},
+		{`[{"op":"add","path":"/list/01","value":1}]`, 0, "/list/01", ErrPatchPathNotFound},
+		{`[{"op":"add","path":"/a/b","value":1}]`, 0, "/a/b", ErrPatchPathNotFound},
+		{`[{"op":"add","path":"/a"}]`, 0, "/a", ErrPatchInvalid},
+		{`[{"op":"copy","path":"/a"}]`, 0, "/a", ErrPatchInvalid},
+		{`[{"op":"move","from":"/list","path":"/list/0"}]`, 0, "/list/0", ErrPatchInvalid},
+		{`[{"op":"merge","path":"/a","value":1}]`, 0, "/a", ErrPatchInvalid},
+		{`[{"op":"add","path":"a","value":1}]`, 0, "a", ErrPatchInvalid},
+	}
+	for _, tt := range tests {
+		_, err := ApplyJSONPatch([]byte(doc), []byte(tt.patch))
+		require.ErrorIs(t, err, tt.err, tt.patch)
+		var patchErr *PatchError
+		require.ErrorAs(t, err, &patchErr)
+		assert.Equal(t, tt.index, patchErr.Index, tt.patch)
+		assert.Equal(t, tt.path, patchErr.Path, tt.patch)
+	}
+
+	_, err := ApplyJSONPatch([]byte(doc), []byte(`{"op":"add"}`))
+	var jsonErr *JSONError
+	assert.ErrorAs(t, err, &jsonErr)
+}
+
+func TestMergePatchBinding(t *testing.T) {
+	nickname := "bob"
+	obj := patchUser{
+		ID: 7, Name: "Bob", Age: 30, Nickname: &nickname,
+		Address: patchAddress{City: "Paris", Zip: "75001"},
+		Tags:    []string{"a", "b"},
+		Labels:  map[string]string{"team": "core", "env": "prod"},
+	}
+	req := requestWithBody(http.MethodPatch, "/",
+		`{"age":31,"nickname":null,"address":{"zip":null},"tags":["c"],"labels":{"env":null,"tier":"1"}}`)
+	req.Header.Set("Content-Type", MIMEMergePatch)
+	require.NoError(t, MergePatch.Bind(req, &obj))
+
+	assert.Equal(t, 7, obj.ID)
+	assert.Equal(t, "Bob", obj.Name)
+	assert.Equal(t, 31, obj.Age)
+	assert.Nil(t, obj.Nickname)
+	assert.Equal(t, patchAddress{City: "Paris"}, obj.Address)
+	assert.Equal(t, []string{"c"}, obj.Tags)
+	assert.Equal(t, map[string]string{"team": "core", "tier": "1"}, obj.Labels)
+}
+
+func TestMergePatchBindingValidation(t *testing.T) {
+	obj := patchUser{Name: "Bob", Age: 30}
+	err := MergePatch.BindBody([]byte(`{"name":null}`), &obj)
+	errs, ok := AsValidationErrors(err)
+	require.True(t, ok)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "name", errs[0].Field)
+	assert.Equal(t, "required", errs[0].Tag)
+	// obj is unchanged when the patched value is invalid.
+	assert.Equal(t, "Bob", obj.Name)
+}
+
+func TestMergePatchBindingTypeError(t *testing.T) {
+	obj := patchUser{Name: "Bob", Age: 30}
+	err := MergePatch.BindBody([]byte(`{"age":"old"}`), &obj)
+	var jsonErr *JSONError
+	require.ErrorAs(t, err, &jsonErr)
+	assert.Equal(t, "age", jsonErr.Field)
+	// obj is unchanged when the patch can not be applied.
+	assert.Equal(t, patchUser{Name: "Bob", Age: 30}, obj)
+
+	assert.Error(t, MergePatch.BindBody([]byte(`{}`), obj))
+}
+
+func TestJSONPatchBinding(t *testing.T) {
+	obj := patchUser{ID: 7, Name: "Bob", Age: 30, Tags: []string{"a", "b"}}
+	req := requestWithBody(http.MethodPatch, "/", `[
+		{"op":"test","path":"/name","value":"Bob"},
+		{"op":"replace","path":"/name","value":"Robert"},
+		{"op":"add","path":"/tags/0","value":"z"},
+		{"op":"add","path":"/labels","value":{"team":"core"}},
+		{"op":"remove","path":"/age"}
+	]`)
+	req.Header.Set("Content-Type", MIMEJSONPatch)
+	require.NoError(t, Default(req.Method, req.Header.Get("Content-Type")).Bind(req, &obj))
+
+	assert.Equal(t, 7, obj.ID)
+	assert.Equal(t, "Robert", obj.Name)
+	assert.Equal(t, 0, obj.Age)
+	assert.Equal(t, []string{"z", "a", "b"}, obj.Tags)
+	assert.Equal(t, map[string]string{"team": "core"}, obj.Labels)
+}
+
+func TestJSONPatchBindingTestFailed(t *testing.T) {
+	obj := patchUser{Name: "Bob", Age: 30}
+	err := JSONPatch.BindBody([]byte(`[
+		{"op":"replace","path":"/age","value":40},
+		{"op":"test","path":"/name","value":"Alice"}
+	]`), &obj)
+	assert.ErrorIs(t, err, ErrPatchTestFailed)
+	assert.EqualError(t, err, `json patch: operation 1: test "/name": test failed`)
+	assert.Equal(t, 30, obj.Age)
+}
+
+func TestJSONPatchBindingValidation(t *testing.T) {
+	obj := patchUser{Name: "Bob", Age: 30}
+	err := JSONPatch.BindBody([]byte(`[{"op":"replace","path":"/age","value":-1}]`), &obj)
+	errs, ok := AsValidationErrors(err)
+	require.True(t, ok)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "age", errs[0].Field)
+	assert.Equal(t, patchUser{Name: "Bob", Age: 30}, obj)
+}
+
+func TestJSONPatchBindingOmitEmpty(t *testing.T) {
+	type patchAccount struct {
+		patchAddress
+		Email string          `json:"email,omitempty"`
+		Owner *patchUser      `json:"owner,omitempty"`
+		Homes []patchAddress  `json:"homes"`
+		Notes map[string]*int `json:"notes,omitempty"`
+		Dash  string          `json:"-,omitempty"`
+	}
+	obj := patchAccount{
+		patchAddress: patchAddress{City: "Paris"},
+		Owner:        &patchUser{Name: "Bob", Address: patchAddress{City: "Lyon"}},
+		Homes:        []patchAddress{{City: "Nice"}},
+	}
+	// the fields omitted as empty exist in the document the patch applies to.
+	require.NoError(t, JSONPatch.BindBody([]byte(`[
+		{"op":"test","path":"/zip","value":""},
+		{"op":"replace","path":"/zip","value":"75001"},
+		{"op":"test","path":"/email","value":""},
+		{"op":"replace","path":"/email","value":"bob@example.com"},
+		{"op":"replace","path":"/owner/address/zip","value":"69001"},
+		{"op":"replace","path":"/homes/0/zip","value":"06000"},
+		{"op":"test","path":"/notes","value":null},
+		{"op":"replace","path":"/notes","value":{"a":1}},
+		{"op":"replace","path":"/-","value":"dash"}
+	]`), &obj))
+
+	one := 1
+	assert.Equal(t, patchAddress{City: "Paris", Zip: "75001"}, obj.patchAddress)
+	assert.Equal(t, "bob@example.com", obj.Email)
+	assert.Equal(t, patchAddress{City: "Lyon", Zip: "69001"}, obj.Owner.Address)
+	assert.Equal(t, []patchAddress{{City: "Nice", Zip: "06000"}}, obj.Homes)
+	assert.Equal(t, map[string]*int{"a": &one}, obj.Notes)
+	assert.Equal(t, "dash", obj.Dash)
+
+	// a nil pointer has no fields to patch.
+	obj.Owner = nil
+	err := JSONPatch.BindBody([]byte(`[{"op":"replace","path":"/owner/name","value":"Bob"}]`), &obj)
+	assert.ErrorIs(t, err, ErrPatchPathNotFound)
+}
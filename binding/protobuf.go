@@ -22,6 +22,14 @@ func (b protobufBinding) Bind(req *http.Request, obj any) error {
 	return b.BindBody(buf, obj)
 }
 
+func (b protobufBinding) decode(r io.Reader, obj any) error {
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return b.BindBody(buf, obj)
+}
+
 func (protobufBinding) BindBody(body []byte, obj any) error {
 	msg, ok := obj.(proto.Message)
 	if !ok {
@@ -0,0 +1,80 @@
+package binding
+
+import (
+	"mime"
+	"strings"
+	"sync"
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Binding{
+		MIMEJSON:              JSON,
+		MIMEXML:               XML,
+		MIMEXML2:              XML,
+		MIMEPOSTForm:          Form,
+		MIMEMultipartPOSTForm: FormMultipart,
+		MIMEPROTOBUF:          ProtoBuf,
+		MIMEYAML:              YAML,
+		MIMETOML:              TOML,
+		MIMEMSGPACK:           MsgPack,
+		MIMEMSGPACK2:          MsgPack,
+		MIMECBOR:              CBOR,
+		MIMEMergePatch:        MergePatch,
+		MIMEJSONPatch:         JSONPatch,
+		"+json":               JSON,
+		"+xml":                XML,
+		"+yaml":               YAML,
+		"+cbor":               CBOR,
+	}
+)
+
+// Register makes b the binding Default selects for mediaType, e.g. the vendor type
+// "application/vnd.acme.v2+csv". A structured syntax suffix such as "+json" registers b for
+// every media type with this suffix and no binding of its own, so "application/vnd.acme.v2+json"
+// binds with JSON without registration. Register replaces the binding of mediaType, a nil
+// binding removes it.
+func Register(mediaType string, b Binding) {
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if b == nil {
+		delete(registry, mediaType)
+		return
+	}
+	registry[mediaType] = b
+}
+
+// Lookup returns the binding registered for contentType, a Content-Type header value which
+// may carry parameters, falling back on the binding of its structured syntax suffix.
+func Lookup(contentType string) (Binding, bool) {
+	mediaType := parseMediaType(contentType)
+
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	if b, ok := registry[mediaType]; ok {
+		return b, true
+	}
+	if i := strings.LastIndexByte(mediaType, '+'); i > 0 {
+		if b, ok := registry[mediaType[i:]]; ok {
+			return b, true
+		}
+	}
+	return nil, false
+}
+
+// parseMediaType returns the lower case media type of contentType, without its parameters.
+func parseMediaType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil && mediaType == "" {
+		mediaType, _, _ = strings.Cut(contentType, ";")
+		mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	}
+	return mediaType
+}
+
+// mediaTypeCharset returns the lower case charset parameter of contentType, if any.
+func mediaTypeCharset(contentType string) string {
+	_, params, _ := mime.ParseMediaType(contentType)
+	return strings.ToLower(params["charset"])
+}
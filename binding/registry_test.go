@@ -0,0 +1,83 @@
+package binding
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBindingDefaultMediaTypeParameters(t *testing.T) {
+	assert.Equal(t, JSON, Default("POST", "application/json; charset=utf-8"))
+	assert.Equal(t, JSON, Default("POST", "Application/JSON;charset=UTF-8"))
+	assert.Equal(t, XML, Default("POST", "text/xml; charset=iso-8859-1"))
+	assert.Equal(t, FormMultipart, Default("POST", "multipart/form-data; boundary=abc"))
+	assert.Equal(t, JSON, Default("POST", "application/json; charset"))
+	assert.Equal(t, Form, Default("POST", ""))
+	assert.Equal(t, Form, Default("POST", "application/octet-stream"))
+}
+
+func TestBindingDefaultSuffix(t *testing.T) {
+	assert.Equal(t, JSON, Default("POST", "application/problem+json"))
+	assert.Equal(t, JSON, Default("POST", "application/vnd.acme.v2+json; charset=utf-8"))
+	assert.Equal(t, XML, Default("POST", "application/atom+xml"))
+	assert.Equal(t, YAML, Default("POST", "application/vnd.acme+yaml"))
+	assert.Equal(t, CBOR, Default("POST", "application/vnd.acme+cbor"))
+}
+
+func TestRegister(t *testing.T) {
+	vendor := NewJSON(JSONOptions{DisallowUnknownFields: true})
+	Register("application/vnd.acme.v2+json", vendor)
+	defer Register("application/vnd.acme.v2+json", nil)
+
+	assert.Equal(t, vendor, Default("POST", "application/vnd.acme.v2+json"))
+	assert.Equal(t, JSON, Default("POST", "application/vnd.acme.v1+json"))
+
+	b, ok := Lookup("Application/Vnd.Acme.V2+JSON; charset=utf-8")
+	assert.True(t, ok)
+	assert.Equal(t, vendor, b)
+
+	Register("application/vnd.acme.v2+json", nil)
+	assert.Equal(t, JSON, Default("POST", "application/vnd.acme.v2+json"))
+
+	_, ok = Lookup("application/octet-stream")
+	assert.False(t, ok)
+}
+
+func TestBindingFormCharset(t *testing.T) {
+	// "café" and "naïve" in ISO-8859-1
+	req := requestWithBody(http.MethodPost, "/", "foo=caf%E9&bar=na%EFve")
+	req.Header.Set("Content-Type", MIMEPOSTForm+"; charset=ISO-8859-1")
+	var obj FooBarStruct
+	require.NoError(t, FormPost.Bind(req, &obj))
+	assert.Equal(t, "café", obj.Foo)
+	assert.Equal(t, "naïve", obj.Bar)
+
+	req = requestWithBody(http.MethodPost, "/?baz=1", "foo=caf%E9&bar=x")
+	req.Header.Set("Content-Type", MIMEPOSTForm+"; charset=windows-1252")
+	obj = FooBarStruct{}
+	require.NoError(t, Form.Bind(req, &obj))
+	assert.Equal(t, "café", obj.Foo)
+
+	req = requestWithBody(http.MethodPost, "/", "foo=bar&bar=foo")
+	req.Header.Set("Content-Type", MIMEPOSTForm+"; charset=x-unknown")
+	assert.Error(t, FormPost.Bind(req, &obj))
+}
+
+func TestBindingXMLCharset(t *testing.T) {
+	var obj FooStruct
+	req := requestWithBody(http.MethodPost, "/", "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><map><foo>caf\xe9</foo></map>")
+	require.NoError(t, XML.Bind(req, &obj))
+	assert.Equal(t, "café", obj.Foo)
+
+	obj = FooStruct{}
+	req = requestWithBody(http.MethodPost, "/", "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><map><foo>na\xefve</foo></map>")
+	req.Header.Set("Content-Type", MIMEXML+"; charset=iso-8859-1")
+	require.NoError(t, XML.Bind(req, &obj))
+	assert.Equal(t, "naïve", obj.Foo)
+
+	obj = FooStruct{}
+	require.NoError(t, XML.BindBody([]byte("<?xml version=\"1.0\" encoding=\"windows-1252\"?><map><foo>caf\xe9</foo></map>"), &obj))
+	assert.Equal(t, "café", obj.Foo)
+}
@@ -0,0 +1,150 @@
+package binding
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrJSONNotArray is reported when a JSON stream body is not an array.
+	ErrJSONNotArray = errors.New("body is not an array")
+	// ErrStreamItemTooLarge is reported when an item of a stream is larger than MaxStreamItemBytes.
+	ErrStreamItemTooLarge = errors.New("item too large")
+)
+
+// MaxStreamItemBytes limits the size of each item of a stream, zero or less means no limit.
+var MaxStreamItemBytes int64 = 1 << 20 // 1 MB
+
+// StreamError is an error decoding or validating an item of a stream, with its index.
+type StreamError struct {
+	// Index is the index of the failing item, counted from zero.
+	Index int
+	Err   error
+}
+
+func (e *StreamError) Error() string {
+	return fmt.Sprintf("item %d: %v", e.Index, e.Err)
+}
+
+func (e *StreamError) Unwrap() error {
+	return e.Err
+}
+
+// Stream calls fn with each item of the body of req, an application/x-ndjson stream or an
+// application/json (or +json) array. The items are decoded like JSON and validated one at a time, so
+// the memory used is bounded by the size of the largest item, whatever the size of the body.
+// An item larger than MaxStreamItemBytes fails with ErrStreamItemTooLarge.
+//
+// Stream stops at the first error. The errors of the items are returned as *StreamError, the
+// errors of fn are returned as is.
+func Stream[T any](req *http.Request, fn func(item *T) error) error {
+	if req == nil || req.Body == nil {
+		return errors.New("invalid request")
+	}
+	switch mediaType := parseMediaType(req.Header.Get("Content-Type")); {
+	case mediaType == MIMENDJSON:
+		return stream(req.Context(), req.Body, false, fn)
+	case mediaType == MIMEJSON, strings.HasSuffix(mediaType, "+json"):
+		return stream(req.Context(), req.Body, true, fn)
+	default:
+		return fmt.Errorf("unsupported content type %q for a stream", mediaType)
+	}
+}
+
+// StreamNDJSON calls fn with each JSON value of r, see Stream.
+func StreamNDJSON[T any](r io.Reader, fn func(item *T) error) error {
+	return stream(context.Background(), r, false, fn)
+}
+
+// StreamJSONArray calls fn with each element of the JSON array of r, see Stream.
+func StreamJSONArray[T any](r io.Reader, fn func(item *T) error) error {
+	return stream(context.Background(), r, true, fn)
+}
+
+// stream splits r into items with encoding/json, which buffers one item at a time, and
+// decodes them with the JSON binding.
+func stream[T any](ctx context.Context, r io.Reader, array bool, fn func(item *T) error) error {
+	limited := &itemLimitReader{r: r, max: MaxStreamItemBytes}
+	limited.reset()
+	dec := json.NewDecoder(limited)
+	if array {
+		tok, err := dec.Token()
+		if err != nil {
+			return jsonDecodeError(err)
+		}
+		if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+			return &JSONError{Err: ErrJSONNotArray}
+		}
+	}
+
+	for index := 0; ; index++ {
+		limited.reset()
+		if array && !dec.More() {
+			break
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			if !array && err == io.EOF {
+				return nil
+			}
+			return &StreamError{Index: index, Err: jsonDecodeError(err)}
+		}
+		item := new(T)
+		if err := (jsonBinding{}).decodeJSON(ctx, bytes.NewReader(raw), item); err != nil {
+			return &StreamError{Index: index, Err: err}
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+
+	// the closing bracket
+	if _, err := dec.Token(); err != nil {
+		return jsonDecodeError(err)
+	}
+	// only white space may follow the array.
+	offset := dec.InputOffset()
+	limited.reset()
+	_, err := dec.Token()
+	var syntaxErr *json.SyntaxError
+	switch {
+	case err == io.EOF:
+		return nil
+	case err == nil, errors.As(err, &syntaxErr):
+		return &JSONError{Offset: offset, Err: ErrJSONTrailingData}
+	}
+	return jsonDecodeError(err)
+}
+
+// itemLimitReader fails reading more than max bytes since its last reset. The decoder keeps
+// what it read past the previous item, so an item is at most about twice max in memory.
+type itemLimitReader struct {
+	r   io.Reader
+	max int64
+	n   int64
+}
+
+func (l *itemLimitReader) reset() {
+	l.n = l.max
+}
+
+func (l *itemLimitReader) Read(p []byte) (int, error) {
+	if l.max <= 0 {
+		return l.r.Read(p)
+	}
+	if l.n <= 0 {
+		return 0, ErrStreamItemTooLarge
+	}
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	return n, err
+}
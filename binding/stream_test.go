@@ -0,0 +1,159 @@
+package binding
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func streamRequest(contentType, body string) *http.Request {
+	req := requestWithBody(http.MethodPost, "/", body)
+	req.Header.Set("Content-Type", contentType)
+	return req
+}
+
+func collectStream(req *http.Request) ([]string, error) {
+	var foos []string
+	err := Stream(req, func(item *FooStruct) error {
+		foos = append(foos, item.Foo)
+		return nil
+	})
+	return foos, err
+}
+
+func TestStreamNDJSON(t *testing.T) {
+	foos, err := collectStream(streamRequest(MIMENDJSON, "{\"foo\":\"a\"}\n{\"foo\":\"b\"}\n\n{\"foo\":\"c\"}"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, foos)
+
+	foos, err = collectStream(streamRequest(MIMENDJSON, ""))
+	require.NoError(t, err)
+	assert.Empty(t, foos)
+}
+
+func TestStreamJSONArray(t *testing.T) {
+	foos, err := collectStream(streamRequest(MIMEJSON+"; charset=utf-8", `[{"foo":"a"}, {"foo":"b"}]`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, foos)
+
+	foos, err = collectStream(streamRequest(MIMEJSON, ` [] `))
+	require.NoError(t, err)
+	assert.Empty(t, foos)
+
+	_, err = collectStream(streamRequest(MIMEJSON, `{"foo":"a"}`))
+	assert.ErrorIs(t, err, ErrJSONNotArray)
+
+	_, err = collectStream(streamRequest(MIMEJSON, `[{"foo":"a"}`))
+	var jsonErr *JSONError
+	assert.ErrorAs(t, err, &jsonErr)
+
+	_, err = collectStream(streamRequest(MIMEXML, `<foo/>`))
+	assert.Error(t, err)
+}
+
+func TestStreamItemErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name, contentType, body string
+		index                   int
+	}{
+		{"validation", MIMEJSON, `[{"foo":"a"},{"foo":""},{"foo":"c"}]`, 1},
+		{"type", MIMEJSON, `[{"foo":"a"},{"foo":"b"},{"foo":1}]`, 2},
+		{"syntax", MIMENDJSON, "{\"foo\":\"a\"}\n{\"foo\":", 1},
+	} {
+		foos, err := collectStream(streamRequest(tt.contentType, tt.body))
+		var streamErr *StreamError
+		require.ErrorAs(t, err, &streamErr, tt.name)
+		assert.Equal(t, tt.index, streamErr.Index, tt.name)
+		assert.Len(t, foos, tt.index, tt.name)
+	}
+}
+
+func TestStreamItemTooLarge(t *testing.T) {
+	defer func(max int64) { MaxStreamItemBytes = max }(MaxStreamItemBytes)
+	MaxStreamItemBytes = 64
+
+	small := strings.Repeat(`{"foo":"a"}`+"\n", 20)
+	foos, err := collectStream(streamRequest(MIMENDJSON, small))
+	require.NoError(t, err)
+	assert.Len(t, foos, 20)
+
+	large := `{"foo":"` + strings.Repeat("x", 200) + `"}`
+	for _, tt := range []struct {
+		contentType, body string
+	}{
+		{MIMENDJSON, small + large},
+		{MIMEJSON, "[" + strings.Repeat(`{"foo":"a"},`, 20) + large + "]"},
+	} {
+		foos, err = collectStream(streamRequest(tt.contentType, tt.body))
+		assert.ErrorIs(t, err, ErrStreamItemTooLarge, tt.contentType)
+		var streamErr *StreamError
+		require.ErrorAs(t, err, &streamErr, tt.contentType)
+		assert.Equal(t, 20, streamErr.Index, tt.contentType)
+		assert.Len(t, foos, 20, tt.contentType)
+	}
+}
+
+func TestStreamJSONArrayTrailingData(t *testing.T) {
+	foos, err := collectStream(streamRequest(MIMEJSON, "[{\"foo\":\"a\"}] \n\t"))
+	require.NoError(t, err)
+	assert.Len(t, foos, 1)
+
+	for _, trailing := range []string{" garbage", "[]", `{"foo":"b"}`, "]"} {
+		_, err = collectStream(streamRequest(MIMEJSON, `[{"foo":"a"}]`+trailing))
+		assert.ErrorIs(t, err, ErrJSONTrailingData, trailing)
+		var jsonErr *JSONError
+		require.ErrorAs(t, err, &jsonErr, trailing)
+		assert.Equal(t, int64(13), jsonErr.Offset, trailing)
+	}
+}
+
+func TestStreamCallbackError(t *testing.T) {
+	errStop := errors.New("stop")
+	count := 0
+	err := Stream(streamRequest(MIMENDJSON, "{\"foo\":\"a\"}\n{\"foo\":\"b\"}"), func(item *FooStruct) error {
+		count++
+		return errStop
+	})
+	assert.Equal(t, errStop, err)
+	assert.Equal(t, 1, count)
+}
+
+// endlessArray is a JSON array of n items, generated while it is read.
+type endlessArray struct {
+	n, read int
+	pending string
+}
+
+func (a *endlessArray) Read(p []byte) (int, error) {
+	if a.pending == "" {
+		switch {
+		case a.read == 0:
+			a.pending = `[{"foo":"x"}`
+		case a.read < a.n:
+			a.pending = `,{"foo":"x"}`
+		case a.read == a.n:
+			a.pending = "]"
+		default:
+			return 0, io.EOF
+		}
+		a.read++
+	}
+	n := copy(p, a.pending)
+	a.pending = a.pending[n:]
+	return n, nil
+}
+
+func TestStreamJSONArrayLarge(t *testing.T) {
+	count := 0
+	err := StreamJSONArray(&endlessArray{n: 100000}, func(item *FooStruct) error {
+		count++
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 100000, count)
+}
@@ -2,6 +2,7 @@ package binding
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"net/http"
 
@@ -15,16 +16,21 @@ func (tomlBinding) Name() string {
 }
 
 func (tomlBinding) Bind(req *http.Request, obj any) error {
-	return decodeToml(req.Body, obj)
+	return decodeToml(req.Context(), req.Body, obj)
 }
 
 func (tomlBinding) BindBody(body []byte, obj any) error {
-	return decodeToml(bytes.NewReader(body), obj)
+	return decodeToml(context.Background(), bytes.NewReader(body), obj)
 }
 
-func decodeToml(r io.Reader, obj any) error {
-	if _, err := toml.NewDecoder(r).Decode(obj); err != nil {
+func (tomlBinding) decode(r io.Reader, obj any) error {
+	_, err := toml.NewDecoder(r).Decode(obj)
+	return err
+}
+
+func decodeToml(ctx context.Context, r io.Reader, obj any) error {
+	if err := (tomlBinding{}).decode(r, obj); err != nil {
 		return err
 	}
-	return validate(obj)
+	return validateCtx(ctx, obj)
 }
@@ -0,0 +1,83 @@
+package binding
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/fr"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	frTranslations "github.com/go-playground/validator/v10/translations/fr"
+)
+
+// TranslationsFunc registers the messages of a locale on a validator engine, e.g. the
+// RegisterDefaultTranslations functions of the go-playground/validator translations packages.
+type TranslationsFunc func(v *validator.Validate, trans ut.Translator) error
+
+var (
+	translatorsMu sync.RWMutex
+	translators   = ut.New(en.New(), en.New(), fr.New())
+
+	// builtinTranslations are registered on the engine of the default validator, English is
+	// the language of the messages when no locale matches.
+	builtinTranslations = map[string]TranslationsFunc{
+		"en": enTranslations.RegisterDefaultTranslations,
+		"fr": frTranslations.RegisterDefaultTranslations,
+	}
+)
+
+// RegisterLocale makes the validation messages translatable to the locale of translator,
+// registering the messages of register on the engine of Validator, which must be a
+// *validator.Validate. English and French are built in, other locales are registered with
+// the translations of go-playground/validator:
+//
+//	err := binding.RegisterLocale(de.New(), de_translations.RegisterDefaultTranslations)
+//
+// It replaces the messages of a registered locale. Register the locales before serving
+// requests.
+func RegisterLocale(translator locales.Translator, register TranslationsFunc) error {
+	engine, err := validatorEngine()
+	if err != nil {
+		return err
+	}
+
+	translatorsMu.Lock()
+	defer translatorsMu.Unlock()
+	if err := translators.AddTranslator(translator, true); err != nil {
+		return err
+	}
+	trans, _ := translators.GetTranslator(translator.Locale())
+	return register(engine, trans)
+}
+
+// registerBuiltinTranslations registers the messages of the built-in locales on v.
+func registerBuiltinTranslations(v *validator.Validate) {
+	translatorsMu.RLock()
+	defer translatorsMu.RUnlock()
+	for locale, register := range builtinTranslations {
+		trans, _ := translators.GetTranslator(locale)
+		_ = register(v, trans)
+	}
+}
+
+// findTranslator returns the translator of the first of languages with a registered locale,
+// e.g. "fr-CH" matches the locale fr_CH, or else fr. It falls back on English.
+func findTranslator(languages []string) ut.Translator {
+	translatorsMu.RLock()
+	defer translatorsMu.RUnlock()
+	for _, lang := range languages {
+		lang = strings.ReplaceAll(lang, "-", "_")
+		if trans, ok := translators.GetTranslator(lang); ok {
+			return trans
+		}
+		if base, _, ok := strings.Cut(lang, "_"); ok {
+			if trans, ok := translators.GetTranslator(base); ok {
+				return trans
+			}
+		}
+	}
+	return translators.GetFallback()
+}
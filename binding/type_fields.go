@@ -0,0 +1,179 @@
+package binding
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// typeField is a field of a struct type by the name of its tag, as encoding/json resolves it.
+type typeField struct {
+	name string
+	// index and names are the indexes and the Go names of the path to the field through the
+	// embedded structs.
+	index     []int
+	names     []string
+	typ       reflect.Type
+	tagged    bool
+	omitEmpty bool
+}
+
+// typeFields are the fields of a struct type in the order of their declaration.
+type typeFields struct {
+	list   []typeField
+	byName map[string]*typeField
+}
+
+type typeFieldsKey struct {
+	typ reflect.Type
+	tag string
+}
+
+var typeFieldsCache sync.Map // map[typeFieldsKey]*typeFields
+
+// cachedTypeFields returns the fields of struct type t named by tag, including the promoted
+// fields of the embedded structs. Like encoding/json, a shallower field hides the deeper ones
+// of the same name, a tagged field the untagged ones of the same depth, and the other fields
+// of the same name are ambiguous and dropped. The json tag "-" hides a field while "-,"
+// names it "-", the other tags hide the fields named "-" like the form mapping does.
+func cachedTypeFields(t reflect.Type, tag string) *typeFields {
+	key := typeFieldsKey{typ: t, tag: tag}
+	if fields, ok := typeFieldsCache.Load(key); ok {
+		return fields.(*typeFields)
+	}
+	list := resolveTypeFields(t, tag)
+	fields := &typeFields{list: list, byName: make(map[string]*typeField, len(list))}
+	for i := range list {
+		fields.byName[list[i].name] = &list[i]
+	}
+	typeFieldsCache.Store(key, fields)
+	return fields
+}
+
+// lookup returns the field named key, or else the first one named key regardless of case,
+// which folded reports. field is nil if there is none.
+func (fields *typeFields) lookup(key string) (field *typeField, folded bool) {
+	if field, ok := fields.byName[key]; ok {
+		return field, false
+	}
+	for i := range fields.list {
+		if strings.EqualFold(fields.list[i].name, key) {
+			return &fields.list[i], true
+		}
+	}
+	return nil, false
+}
+
+func resolveTypeFields(t reflect.Type, tag string) []typeField {
+	var fields []typeField
+	// the embedded structs to walk at the current and the next depth, with how many times
+	// they are embedded at that depth.
+	var current []typeField
+	next := []typeField{{typ: t}}
+	var count map[reflect.Type]int
+	nextCount := map[reflect.Type]int{}
+	visited := map[reflect.Type]bool{}
+
+	for len(next) > 0 {
+		current, next = next, current[:0]
+		count, nextCount = nextCount, map[reflect.Type]int{}
+		for _, f := range current {
+			if visited[f.typ] {
+				continue
+			}
+			visited[f.typ] = true
+
+			for i := 0; i < f.typ.NumField(); i++ {
+				sf := f.typ.Field(i)
+				ft := sf.Type
+				if ft.Name() == "" && ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+				if sf.Anonymous {
+					if !sf.IsExported() && ft.Kind() != reflect.Struct {
+						continue
+					}
+				} else if !sf.IsExported() {
+					continue
+				}
+				tagValue := sf.Tag.Get(tag)
+				name, opts := head(tagValue, ",")
+				if tagValue == "-" || (tag != "json" && name == "-") {
+					continue
+				}
+				index := append(append([]int(nil), f.index...), i)
+				names := append(append([]string(nil), f.names...), sf.Name)
+
+				if name == "" && sf.Anonymous && ft.Kind() == reflect.Struct {
+					nextCount[ft]++
+					if nextCount[ft] == 1 {
+						next = append(next, typeField{index: index, names: names, typ: ft})
+					}
+					continue
+				}
+				if !sf.IsExported() {
+					continue
+				}
+				field := typeField{
+					name:      name,
+					index:     index,
+					names:     names,
+					typ:       sf.Type,
+					tagged:    name != "",
+					omitEmpty: strings.Contains(","+opts+",", ",omitempty,"),
+				}
+				if name == "" {
+					field.name = sf.Name
+				}
+				fields = append(fields, field)
+				if count[f.typ] > 1 {
+					// the struct is embedded more than once at this depth, so its fields are
+					// ambiguous: adding them twice drops them below.
+					fields = append(fields, field)
+				}
+			}
+		}
+	}
+
+	sort.Slice(fields, func(i, j int) bool {
+		x, y := fields[i], fields[j]
+		if x.name != y.name {
+			return x.name < y.name
+		}
+		if len(x.index) != len(y.index) {
+			return len(x.index) < len(y.index)
+		}
+		if x.tagged != y.tagged {
+			return x.tagged
+		}
+		return indexLess(x.index, y.index)
+	})
+	dominant := make([]typeField, 0, len(fields))
+	for i := 0; i < len(fields); {
+		n := 1
+		for i+n < len(fields) && fields[i+n].name == fields[i].name {
+			n++
+		}
+		if n == 1 || len(fields[i+1].index) > len(fields[i].index) || fields[i].tagged != fields[i+1].tagged {
+			dominant = append(dominant, fields[i])
+		}
+		i += n
+	}
+	sort.Slice(dominant, func(i, j int) bool {
+		return indexLess(dominant[i].index, dominant[j].index)
+	})
+	return dominant
+}
+
+func indexLess(x, y []int) bool {
+	for i := range x {
+		if i >= len(y) {
+			return false
+		}
+		if x[i] != y[i] {
+			return x[i] < y[i]
+		}
+	}
+	return len(x) < len(y)
+}
@@ -0,0 +1,86 @@
+package binding
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type typeFieldsA struct {
+	Name  string `json:"name"`
+	Both  string
+	Tag   string
+	Inner string `json:"inner,omitempty"`
+}
+
+type typeFieldsB struct {
+	Both string
+	Tag  string `json:"Tag"`
+}
+
+type typeFieldsOuter struct {
+	typeFieldsA
+	*typeFieldsB
+	Name   int    `json:"name"`
+	Dash   string `json:"-,"`
+	Hidden string `json:"-" form:"-,omitempty"`
+	Plain  string `form:"plain"`
+	hidden string
+}
+
+type typeFieldsC struct{ typeFieldsA }
+
+type typeFieldsD struct{ typeFieldsA }
+
+type typeFieldsTwice struct {
+	typeFieldsC
+	typeFieldsD
+	Other string `json:"other"`
+}
+
+func typeFieldNames(fields *typeFields) []string {
+	var names []string
+	for _, f := range fields.list {
+		names = append(names, f.name)
+	}
+	return names
+}
+
+func TestTypeFields(t *testing.T) {
+	fields := cachedTypeFields(reflect.TypeOf(typeFieldsOuter{}), "json")
+	// Both is ambiguous, Tag is tagged in typeFieldsB only and name is shadowed by the outer field.
+	assert.Equal(t, []string{"inner", "Tag", "name", "-", "Plain"}, typeFieldNames(fields))
+	assert.Same(t, fields, cachedTypeFields(reflect.TypeOf(typeFieldsOuter{}), "json"))
+
+	name, folded := fields.lookup("name")
+	require.NotNil(t, name)
+	assert.False(t, folded)
+	assert.Equal(t, []int{2}, name.index)
+	assert.Equal(t, reflect.TypeOf(0), name.typ)
+
+	tag, _ := fields.lookup("Tag")
+	require.NotNil(t, tag)
+	assert.Equal(t, []string{"typeFieldsB", "Tag"}, tag.names)
+	assert.Equal(t, []int{1, 1}, tag.index)
+
+	inner, folded := fields.lookup("INNER")
+	require.NotNil(t, inner)
+	assert.True(t, folded)
+	assert.True(t, inner.omitEmpty)
+
+	missing, _ := fields.lookup("Both")
+	assert.Nil(t, missing)
+
+	form := cachedTypeFields(reflect.TypeOf(typeFieldsOuter{}), "form")
+	assert.Equal(t, []string{"Inner", "Name", "Dash", "plain"}, typeFieldNames(form))
+	hidden, _ := form.lookup("Hidden")
+	assert.Nil(t, hidden)
+}
+
+func TestTypeFieldsEmbeddedTwice(t *testing.T) {
+	// typeFieldsA is embedded twice at the same depth, so all its fields are ambiguous.
+	fields := cachedTypeFields(reflect.TypeOf(typeFieldsTwice{}), "json")
+	assert.Equal(t, []string{"other"}, typeFieldNames(fields))
+}
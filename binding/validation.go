@@ -0,0 +1,127 @@
+package binding
+
+import (
+	"errors"
+	"reflect"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+)
+
+// The functions below register validations on the engine of Validator, which must be a
+// *validator.Validate, as the default validator's is. They may be called before the first
+// validation, the default validator is then initialized by the first of them. Register the
+// validations before serving requests, the engine is not safe for concurrent registrations.
+
+// validatorEngine returns the engine of Validator.
+func validatorEngine() (*validator.Validate, error) {
+	if Validator == nil {
+		return nil, errors.New("binding: no validator")
+	}
+	engine, ok := Validator.Engine().(*validator.Validate)
+	if !ok {
+		return nil, errors.New("binding: the validator engine is not a *validator.Validate")
+	}
+	return engine, nil
+}
+
+// RegisterValidation adds the validation tag, e.g. "iban":
+//
+//	err := binding.RegisterValidation("iban", func(fl validator.FieldLevel) bool {
+//		return iban.Valid(fl.Field().String())
+//	})
+//
+// fn is not called for nil pointers, unless callValidationEvenIfNull is true. An existing tag
+// is replaced.
+func RegisterValidation(tag string, fn validator.Func, callValidationEvenIfNull ...bool) error {
+	engine, err := validatorEngine()
+	if err != nil {
+		return err
+	}
+	return engine.RegisterValidation(tag, fn, callValidationEvenIfNull...)
+}
+
+// RegisterValidationCtx is RegisterValidation for validations using the context of the
+// request, e.g. to check the uniqueness of a value in a database. The context is
+// context.Background() for the bindings without request, such as BindBody.
+func RegisterValidationCtx(tag string, fn validator.FuncCtx, callValidationEvenIfNull ...bool) error {
+	engine, err := validatorEngine()
+	if err != nil {
+		return err
+	}
+	return engine.RegisterValidationCtx(tag, fn, callValidationEvenIfNull...)
+}
+
+// RegisterCrossFieldValidation adds the validation tag comparing a field to the field named
+// by the tag parameter, e.g. `binding:"after=StartDate"`. The other field is looked up from
+// the struct of the field, as by the eqfield tag. The validation fails if it does not exist.
+func RegisterCrossFieldValidation(tag string, fn func(field, other reflect.Value) bool) error {
+	return RegisterValidation(tag, func(fl validator.FieldLevel) bool {
+		other, _, _, ok := fl.GetStructFieldOKAdvanced2(fl.Parent(), fl.Param())
+		if !ok {
+			return false
+		}
+		return fn(fl.Field(), other)
+	}, true)
+}
+
+// RegisterAlias adds alias as a shorthand of tags, e.g. "e164" for "startswith=+,max=16".
+// The errors of the alias report the alias as their tag. It panics if alias is a tag of
+// the validator syntax.
+func RegisterAlias(alias, tags string) error {
+	engine, err := validatorEngine()
+	if err != nil {
+		return err
+	}
+	engine.RegisterAlias(alias, tags)
+	return nil
+}
+
+// RegisterStructValidation adds fn as a validation of the structs of types, for rules across
+// several fields. fn reports the invalid fields with sl.ReportError.
+func RegisterStructValidation(fn validator.StructLevelFunc, types ...any) error {
+	engine, err := validatorEngine()
+	if err != nil {
+		return err
+	}
+	engine.RegisterStructValidation(fn, types...)
+	return nil
+}
+
+// RegisterStructValidationCtx is RegisterStructValidation with the context of the request.
+func RegisterStructValidationCtx(fn validator.StructLevelFuncCtx, types ...any) error {
+	engine, err := validatorEngine()
+	if err != nil {
+		return err
+	}
+	engine.RegisterStructValidationCtx(fn, types...)
+	return nil
+}
+
+// RegisterMessage sets the message of the errors of tag in locale, "en" or a locale added with
+// RegisterLocale. In text, {0} is replaced with the name of the field and {1} with the
+// parameter of the tag:
+//
+//	err := binding.RegisterMessage("en", "tenant_slug", "{0} must be a valid tenant slug")
+func RegisterMessage(locale, tag, text string) error {
+	engine, err := validatorEngine()
+	if err != nil {
+		return err
+	}
+
+	translatorsMu.RLock()
+	trans, ok := translators.GetTranslator(locale)
+	translatorsMu.RUnlock()
+	if !ok {
+		return errors.New("binding: unknown locale " + locale)
+	}
+	return engine.RegisterTranslation(tag, trans, func(trans ut.Translator) error {
+		return trans.Add(tag, text, true)
+	}, func(trans ut.Translator, fe validator.FieldError) string {
+		msg, err := trans.T(tag, fe.Field(), fe.Param())
+		if err != nil {
+			return fe.Error()
+		}
+		return msg
+	})
+}
@@ -0,0 +1,170 @@
+package binding
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError is the failed validation of a field, serialisable as JSON for API responses.
+type FieldError struct {
+	// Field is the path of the field by the names of its json, form, uri, header or cookie
+	// tags, e.g. "items[0].sku", see FieldName.
+	Field string `json:"field"`
+	// Tag is the failed validation tag, e.g. "required" or "max".
+	Tag string `json:"tag"`
+	// Param is the parameter of the tag, e.g. "32" for "max=32".
+	Param string `json:"param,omitempty"`
+	// Value is the invalid value. It is not serialised, as it may be a secret such as a
+	// password, copy it into the response explicitly where that is safe.
+	Value any `json:"-"`
+	// Message describes the error, in English unless translated.
+	Message string `json:"message"`
+
+	err validator.FieldError
+}
+
+func (e *FieldError) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the validator.FieldError of go-playground/validator.
+func (e *FieldError) Unwrap() error {
+	if e.err == nil {
+		return nil
+	}
+	return e.err
+}
+
+// Translate returns a copy of e with the message in the first of languages, e.g. "fr-CH" or
+// "fr", with a registered locale, see RegisterLocale.
+func (e *FieldError) Translate(languages ...string) *FieldError {
+	return e.translate(findTranslator(languages))
+}
+
+func (e *FieldError) translate(trans ut.Translator) *FieldError {
+	t := *e
+	if e.err != nil {
+		t.Message = e.err.Translate(trans)
+	}
+	return &t
+}
+
+// ValidationErrors are the failed validations of a struct, returned by the default validator.
+type ValidationErrors []*FieldError
+
+// Error concatenates the messages of the errors separated by \n.
+func (errs ValidationErrors) Error() string {
+	s := make([]string, len(errs))
+	for i, err := range errs {
+		s[i] = err.Error()
+	}
+	return strings.Join(s, "\n")
+}
+
+// Unwrap returns the errors, so errors.As finds the validator.FieldError of each of them.
+func (errs ValidationErrors) Unwrap() []error {
+	s := make([]error, len(errs))
+	for i, err := range errs {
+		s[i] = err
+	}
+	return s
+}
+
+// Translate returns a copy of errs with the messages in the first of languages with a
+// registered locale, see RegisterLocale.
+func (errs ValidationErrors) Translate(languages ...string) ValidationErrors {
+	trans := findTranslator(languages)
+	t := make(ValidationErrors, len(errs))
+	for i, err := range errs {
+		t[i] = err.translate(trans)
+	}
+	return t
+}
+
+// AsValidationErrors returns the validation errors of err, the error of a binding, which may
+// be a ValidationErrors, a SliceValidationError, the SourceErrors of All or the
+// validator.ValidationErrors of a custom Validator.
+func AsValidationErrors(err error) (ValidationErrors, bool) {
+	var sourceErrs SourceErrors
+	if errors.As(err, &sourceErrs) {
+		var errs ValidationErrors
+		for _, se := range sourceErrs {
+			var fe *FieldError
+			if errors.As(se.Err, &fe) {
+				errs = append(errs, fe)
+			}
+		}
+		return errs, errs != nil
+	}
+	var sliceErrs SliceValidationError
+	if errors.As(err, &sliceErrs) {
+		errs := sliceErrs.ValidationErrors()
+		return errs, errs != nil
+	}
+	var errs ValidationErrors
+	if errors.As(err, &errs) {
+		return errs, true
+	}
+	// the errors of a custom Validator using go-playground/validator.
+	var verrs validator.ValidationErrors
+	if errors.As(err, &verrs) {
+		return newValidationErrors(verrs), true
+	}
+	return nil, false
+}
+
+// newValidationErrors converts the errors of go-playground/validator, with English messages.
+func newValidationErrors(verrs validator.ValidationErrors) ValidationErrors {
+	trans := findTranslator(nil)
+	errs := make(ValidationErrors, len(verrs))
+	for i, fe := range verrs {
+		field := fe.Namespace()
+		// the namespace starts with the name of the validated struct.
+		if _, f, ok := strings.Cut(field, "."); ok {
+			field = f
+		}
+		errs[i] = &FieldError{
+			Field:   field,
+			Tag:     fe.Tag(),
+			Param:   fe.Param(),
+			Value:   fe.Value(),
+			Message: fe.Translate(trans),
+			err:     fe,
+		}
+	}
+	return errs
+}
+
+// fieldNameTags are the tags naming the fields in validation errors, by precedence.
+var fieldNameTags = []string{"json", "form", "uri", "header", "cookie"}
+
+// FieldName returns the name of the field in the validation errors, the name of its first
+// json, form, uri, header or cookie tag, or else its Go name.
+func FieldName(field reflect.StructField) string {
+	for _, tag := range fieldNameTags {
+		if name, _ := head(field.Tag.Get(tag), ","); name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// prefixField returns errs with index prefixed to their fields, e.g. "[2].email".
+func prefixField(errs ValidationErrors, index int) ValidationErrors {
+	prefixed := make(ValidationErrors, len(errs))
+	for i, err := range errs {
+		e := *err
+		if strings.HasPrefix(e.Field, "[") {
+			e.Field = "[" + strconv.Itoa(index) + "]" + e.Field
+		} else {
+			e.Field = "[" + strconv.Itoa(index) + "]." + e.Field
+		}
+		prefixed[i] = &e
+	}
+	return prefixed
+}
@@ -1,6 +1,8 @@
 package binding
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
@@ -25,12 +27,29 @@ type StructValidator interface {
 	Engine() any
 }
 
+// ContextValidator is implemented by the StructValidators validating with the context of the
+// request, e.g. for the validations registered with RegisterValidationCtx.
+type ContextValidator interface {
+	StructValidator
+
+	// ValidateStructCtx is ValidateStruct with the context of the request, or
+	// context.Background() when the binding has no request, e.g. BindBody.
+	ValidateStructCtx(ctx context.Context, obj any) error
+}
+
 var Validator StructValidator = &defaultValidator{}
 
 func validate(obj any) error {
+	return validateCtx(context.Background(), obj)
+}
+
+func validateCtx(ctx context.Context, obj any) error {
 	if Validator == nil {
 		return nil
 	}
+	if v, ok := Validator.(ContextValidator); ok {
+		return v.ValidateStructCtx(ctx, obj)
+	}
 	return Validator.ValidateStruct(obj)
 }
 
@@ -39,7 +58,13 @@ type defaultValidator struct {
 	validate *validator.Validate
 }
 
+var _ ContextValidator = (*defaultValidator)(nil)
+
 func (v *defaultValidator) ValidateStruct(obj any) error {
+	return v.ValidateStructCtx(context.Background(), obj)
+}
+
+func (v *defaultValidator) ValidateStructCtx(ctx context.Context, obj any) error {
 	if obj == nil {
 		return nil
 	}
@@ -47,17 +72,20 @@ func (v *defaultValidator) ValidateStruct(obj any) error {
 	value := reflect.ValueOf(obj)
 	switch value.Kind() {
 	case reflect.Pointer:
-		return v.ValidateStruct(value.Elem().Interface())
+		return v.ValidateStructCtx(ctx, value.Elem().Interface())
 	case reflect.Struct:
-		return v.validateStruct(obj)
+		return v.validateStruct(ctx, obj)
 	case reflect.Slice, reflect.Array:
-		validateErr := make(SliceValidationError, 0)
+		// the errors are kept at the index of their element, nil for the valid ones.
+		validateErr := make(SliceValidationError, value.Len())
+		invalid := false
 		for i := 0; i < value.Len(); i++ {
-			if err := v.ValidateStruct(value.Index(i).Interface()); err != nil {
-				validateErr = append(validateErr, err)
+			if err := v.ValidateStructCtx(ctx, value.Index(i).Interface()); err != nil {
+				validateErr[i] = err
+				invalid = true
 			}
 		}
-		if len(validateErr) == 0 {
+		if !invalid {
 			return nil
 		}
 		return validateErr
@@ -66,9 +94,14 @@ func (v *defaultValidator) ValidateStruct(obj any) error {
 	}
 }
 
-func (v *defaultValidator) validateStruct(obj any) error {
+func (v *defaultValidator) validateStruct(ctx context.Context, obj any) error {
 	v.lazyInit()
-	return v.validate.Struct(obj)
+	err := v.validate.StructCtx(ctx, obj)
+	var verrs validator.ValidationErrors
+	if errors.As(err, &verrs) {
+		return newValidationErrors(verrs)
+	}
+	return err
 }
 
 func (v *defaultValidator) Engine() any {
@@ -80,29 +113,50 @@ func (v *defaultValidator) lazyInit() {
 	v.once.Do(func() {
 		v.validate = validator.New()
 		v.validate.SetTagName("binding")
+		v.validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+			return FieldName(field)
+		})
+		registerBuiltinTranslations(v.validate)
 	})
 }
 
+// SliceValidationError holds the errors of the invalid elements of a slice or an array, by index.
 type SliceValidationError []error
 
 // Error concatenates all error elements in SliceValidationError into a single string separated by \n.
 func (err SliceValidationError) Error() string {
-	n := len(err)
-	if n == 0 {
-		return ""
+	var builder strings.Builder
+	for i, e := range err {
+		if e == nil {
+			continue
+		}
+		if builder.Len() > 0 {
+			builder.WriteString("\n")
+		}
+		fmt.Fprintf(&builder, "[%d]: %s", i, e.Error())
 	}
+	return builder.String()
+}
 
-	var builder strings.Builder
-	if err[0] != nil {
-		fmt.Fprintf(&builder, "[%d]: %s", 0, err[0].Error())
+// Unwrap returns the errors of the elements.
+func (err SliceValidationError) Unwrap() []error {
+	errs := make([]error, 0, len(err))
+	for _, e := range err {
+		if e != nil {
+			errs = append(errs, e)
+		}
 	}
-	if n > 1 {
-		for i := 1; i < n; i++ {
-			if err[i] != nil {
-				builder.WriteString("\n")
-				fmt.Fprintf(&builder, "[%d]: %s", i, err[i].Error())
-			}
+	return errs
+}
+
+// ValidationErrors returns the validation errors of the elements, with the index of their
+// element prefixed to their fields, e.g. "[2].email".
+func (err SliceValidationError) ValidationErrors() ValidationErrors {
+	var errs ValidationErrors
+	for i, e := range err {
+		if elemErrs, ok := AsValidationErrors(e); ok {
+			errs = append(errs, prefixField(elemErrs, i)...)
 		}
 	}
-	return builder.String()
+	return errs
 }
@@ -2,12 +2,20 @@ package binding
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"net/http"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/go-playground/locales/es"
 	"github.com/go-playground/validator/v10"
+	esTranslations "github.com/go-playground/validator/v10/translations/es"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type testInterface interface {
@@ -295,3 +303,209 @@ func TestDefaultValidator(t *testing.T) {
 		})
 	}
 }
+
+type validationUser struct {
+	Email string `json:"email" binding:"required,email"`
+	Name  string `form:"name" binding:"max=3"`
+	Items []struct {
+		SKU string `json:"sku" binding:"required"`
+	} `json:"items" binding:"dive"`
+	Age int `binding:"gte=18"`
+}
+
+func TestValidationErrors(t *testing.T) {
+	obj := validationUser{Email: "x", Name: "abcd", Age: 20}
+	obj.Items = append(obj.Items, struct {
+		SKU string `json:"sku" binding:"required"`
+	}{})
+	err := validate(&obj)
+
+	var errs ValidationErrors
+	require.ErrorAs(t, err, &errs)
+	require.Len(t, errs, 3)
+	assert.Equal(t, &FieldError{Field: "email", Tag: "email", Value: "x", Message: "email must be a valid email address", err: errs[0].err}, errs[0])
+	assert.Equal(t, "name", errs[1].Field)
+	assert.Equal(t, "3", errs[1].Param)
+	assert.Equal(t, "name must be a maximum of 3 characters in length", errs[1].Message)
+	assert.Equal(t, "items[0].sku", errs[2].Field)
+	assert.Equal(t, "email must be a valid email address\nname must be a maximum of 3 characters in length\nsku is a required field", err.Error())
+
+	var fe validator.FieldError
+	require.ErrorAs(t, err, &fe)
+	assert.Equal(t, "email", fe.Tag())
+
+	data, err := json.Marshal(errs[1:2])
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"field":"name","tag":"max","param":"3","message":"name must be a maximum of 3 characters in length"}]`, string(data))
+	assert.Equal(t, "abcd", errs[1].Value)
+}
+
+func TestValidationErrorsValueNotSerialised(t *testing.T) {
+	err := validate(&struct {
+		Password string `json:"password" binding:"min=12"`
+	}{Password: "hunter2"})
+	errs, ok := AsValidationErrors(err)
+	require.True(t, ok)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "hunter2", errs[0].Value)
+
+	data, err := json.Marshal(errs)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "hunter2")
+}
+
+func TestValidationErrorsTranslate(t *testing.T) {
+	err := validate(&validationUser{Email: "a@b.c", Age: 10})
+	errs, ok := AsValidationErrors(err)
+	require.True(t, ok)
+
+	assert.Equal(t, "Age doit être 18 ou plus", errs.Translate("fr")[0].Message)
+	assert.Equal(t, "Age doit être 18 ou plus", errs.Translate("de", "fr-CH")[0].Message)
+	assert.Equal(t, "Age must be 18 or greater", errs.Translate("de")[0].Message)
+	assert.Equal(t, "Age must be 18 or greater", errs[0].Message)
+	assert.Equal(t, "Age doit être 18 ou plus", errs[0].Translate("fr_FR").Message)
+}
+
+func TestValidationErrorsSlice(t *testing.T) {
+	err := validate([]validationUser{{Email: "a@b.c", Age: 18}, {Email: "", Age: 18}})
+	assert.Equal(t, "[1]: email is a required field", err.Error())
+
+	errs, ok := AsValidationErrors(err)
+	require.True(t, ok)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "[1].email", errs[0].Field)
+
+	_, ok = AsValidationErrors(errors.New("test"))
+	assert.False(t, ok)
+}
+
+func TestRegisterLocale(t *testing.T) {
+	require.NoError(t, RegisterLocale(es.New(), esTranslations.RegisterDefaultTranslations))
+
+	errs, ok := AsValidationErrors(validate(&validationUser{Email: "a@b.c", Age: 10}))
+	require.True(t, ok)
+	assert.Equal(t, "Age debe ser 18 o mayor", errs.Translate("es-MX")[0].Message)
+}
+
+// withFreshValidator runs f with a new default validator, which is not initialized yet.
+func withFreshValidator(f func()) {
+	saved := Validator
+	Validator = &defaultValidator{}
+	defer func() { Validator = saved }()
+	f()
+}
+
+func TestRegisterValidation(t *testing.T) {
+	withFreshValidator(func() {
+		require.NoError(t, RegisterValidation("tenant_slug", func(fl validator.FieldLevel) bool {
+			return strings.Trim(fl.Field().String(), "abcdefghijklmnopqrstuvwxyz-") == ""
+		}))
+		require.NoError(t, RegisterMessage("en", "tenant_slug", "{0} must be a valid tenant slug"))
+		require.NoError(t, RegisterMessage("fr", "tenant_slug", "{0} doit être un identifiant de locataire"))
+		assert.Error(t, RegisterMessage("xx", "tenant_slug", "{0}"))
+
+		var obj struct {
+			Tenant string `json:"tenant" binding:"tenant_slug"`
+		}
+		require.NoError(t, JSON.BindBody([]byte(`{"tenant":"acme-corp"}`), &obj))
+
+		err := JSON.BindBody([]byte(`{"tenant":"Acme Corp"}`), &obj)
+		var errs ValidationErrors
+		require.ErrorAs(t, err, &errs)
+		assert.Equal(t, "tenant_slug", errs[0].Tag)
+		assert.Equal(t, "tenant must be a valid tenant slug", errs[0].Message)
+		assert.Equal(t, "tenant doit être un identifiant de locataire", errs.Translate("fr")[0].Message)
+	})
+}
+
+type tenantKey struct{}
+
+func TestRegisterValidationCtx(t *testing.T) {
+	withFreshValidator(func() {
+		require.NoError(t, RegisterValidationCtx("unique_email", func(ctx context.Context, fl validator.FieldLevel) bool {
+			taken, _ := ctx.Value(tenantKey{}).([]string)
+			for _, email := range taken {
+				if email == fl.Field().String() {
+					return false
+				}
+			}
+			return true
+		}))
+
+		var obj struct {
+			Email string `json:"email" form:"email" binding:"unique_email"`
+		}
+		ctx := context.WithValue(context.Background(), tenantKey{}, []string{"bob@example.com"})
+		req := requestWithBody(http.MethodPost, "/", `{"email":"bob@example.com"}`).WithContext(ctx)
+		assert.Error(t, JSON.Bind(req, &obj))
+
+		req = requestWithBody(http.MethodPost, "/", "email=bob@example.com").WithContext(ctx)
+		req.Header.Set("Content-Type", MIMEPOSTForm)
+		assert.Error(t, Form.Bind(req, &obj))
+
+		req = requestWithBody(http.MethodPost, "/", `{"email":"alice@example.com"}`).WithContext(ctx)
+		assert.NoError(t, JSON.Bind(req, &obj))
+
+		// without a request, the context carries no value
+		assert.NoError(t, JSON.BindBody([]byte(`{"email":"bob@example.com"}`), &obj))
+	})
+}
+
+func TestRegisterCrossFieldValidation(t *testing.T) {
+	withFreshValidator(func() {
+		require.NoError(t, RegisterCrossFieldValidation("after", func(field, other reflect.Value) bool {
+			return field.Interface().(time.Time).After(other.Interface().(time.Time))
+		}))
+
+		type period struct {
+			Start time.Time `json:"start"`
+			End   time.Time `json:"end" binding:"after=Start"`
+		}
+		assert.NoError(t, JSON.BindBody([]byte(`{"start":"2024-01-01T00:00:00Z","end":"2024-02-01T00:00:00Z"}`), &period{}))
+		err := JSON.BindBody([]byte(`{"start":"2024-02-01T00:00:00Z","end":"2024-01-01T00:00:00Z"}`), &period{})
+		var errs ValidationErrors
+		require.ErrorAs(t, err, &errs)
+		assert.Equal(t, "end", errs[0].Field)
+		assert.Equal(t, "Start", errs[0].Param)
+	})
+}
+
+func TestRegisterAliasAndStructValidation(t *testing.T) {
+	type contact struct {
+		Phone string `json:"phone" binding:"omitempty,e164"`
+		Email string `json:"email"`
+	}
+	withFreshValidator(func() {
+		require.NoError(t, RegisterAlias("e164", "startswith=+,max=16"))
+		require.NoError(t, RegisterStructValidation(func(sl validator.StructLevel) {
+			c := sl.Current().Interface().(contact)
+			if c.Phone == "" && c.Email == "" {
+				sl.ReportError(c.Email, "email", "Email", "phone_or_email", "")
+			}
+		}, contact{}))
+
+		assert.NoError(t, JSON.BindBody([]byte(`{"phone":"+33123456789"}`), &contact{}))
+
+		var errs ValidationErrors
+		require.ErrorAs(t, JSON.BindBody([]byte(`{"phone":"0123456789"}`), &contact{}), &errs)
+		assert.Equal(t, "e164", errs[0].Tag)
+
+		require.ErrorAs(t, JSON.BindBody([]byte(`{}`), &contact{}), &errs)
+		assert.Equal(t, "phone_or_email", errs[0].Tag)
+		assert.Equal(t, "email", errs[0].Field)
+	})
+}
+
+type engineOnlyValidator struct{}
+
+func (engineOnlyValidator) ValidateStruct(any) error { return nil }
+func (engineOnlyValidator) Engine() any              { return nil }
+
+func TestRegisterValidationCustomValidator(t *testing.T) {
+	saved := Validator
+	Validator = engineOnlyValidator{}
+	defer func() { Validator = saved }()
+
+	assert.Error(t, RegisterValidation("iban", func(validator.FieldLevel) bool { return true }))
+	assert.Error(t, RegisterAlias("e164", "startswith=+"))
+}
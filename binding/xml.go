@@ -2,6 +2,7 @@ package binding
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
 	"io"
 	"net/http"
@@ -13,17 +14,40 @@ func (xmlBinding) Name() string {
 	return "xml"
 }
 
+// Bind decodes the body in the charset of the Content-Type of req, or else in the encoding of
+// the XML declaration.
 func (xmlBinding) Bind(req *http.Request, obj any) error {
-	return decodeXML(req.Body, obj)
+	if charset := mediaTypeCharset(req.Header.Get("Content-Type")); !isUTF8(charset) {
+		r, err := charsetReader(charset, req.Body)
+		if err != nil {
+			return err
+		}
+		// the body is UTF-8 now, whatever its declaration says.
+		dec := xml.NewDecoder(r)
+		dec.CharsetReader = func(_ string, r io.Reader) (io.Reader, error) {
+			return r, nil
+		}
+		if err := dec.Decode(obj); err != nil {
+			return err
+		}
+		return validateCtx(req.Context(), obj)
+	}
+	return decodeXML(req.Context(), req.Body, obj)
 }
 
 func (xmlBinding) BindBody(body []byte, obj any) error {
-	return decodeXML(bytes.NewReader(body), obj)
+	return decodeXML(context.Background(), bytes.NewReader(body), obj)
+}
+
+func (xmlBinding) decode(r io.Reader, obj any) error {
+	dec := xml.NewDecoder(r)
+	dec.CharsetReader = charsetReader
+	return dec.Decode(obj)
 }
 
-func decodeXML(r io.Reader, obj any) error {
-	if err := xml.NewDecoder(r).Decode(obj); err != nil {
+func decodeXML(ctx context.Context, r io.Reader, obj any) error {
+	if err := (xmlBinding{}).decode(r, obj); err != nil {
 		return err
 	}
-	return validate(obj)
+	return validateCtx(ctx, obj)
 }
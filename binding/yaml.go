@@ -2,6 +2,7 @@ package binding
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"io"
 	"net/http"
@@ -19,16 +20,20 @@ func (yamlBinding) Bind(req *http.Request, obj any) error {
 	if req == nil || req.Body == nil {
 		return errors.New("invalid request")
 	}
-	return decodeYAML(req.Body, obj)
+	return decodeYAML(req.Context(), req.Body, obj)
 }
 
 func (yamlBinding) BindBody(body []byte, obj any) error {
-	return decodeYAML(bytes.NewReader(body), obj)
+	return decodeYAML(context.Background(), bytes.NewReader(body), obj)
 }
 
-func decodeYAML(r io.Reader, obj any) error {
-	if err := yaml.NewDecoder(r).Decode(obj); err != nil {
+func (yamlBinding) decode(r io.Reader, obj any) error {
+	return yaml.NewDecoder(r).Decode(obj)
+}
+
+func decodeYAML(ctx context.Context, r io.Reader, obj any) error {
+	if err := (yamlBinding{}).decode(r, obj); err != nil {
 		return err
 	}
-	return validate(obj)
+	return validateCtx(ctx, obj)
 }
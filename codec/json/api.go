@@ -0,0 +1,70 @@
+// Package json is the JSON codec of the JSON bindings and renders. It uses encoding/json,
+// or github.com/goccy/go-json when built with the go_json tag:
+//
+//	go build -tags go_json .
+//
+// Another implementation is plugged in with Use, or binding.SetJSONCodec, before serving
+// requests.
+package json
+
+import (
+	"io"
+	"sync/atomic"
+)
+
+// current holds the codec in use as a registered, nil for Default.
+var current atomic.Value
+
+type registered struct {
+	Core
+}
+
+// Default returns the built-in codec, encoding/json or github.com/goccy/go-json.
+func Default() Core {
+	return jsonAPI{}
+}
+
+// API returns the codec used by the JSON bindings and renders.
+func API() Core {
+	if c := current.Load(); c != nil && c.(registered).Core != nil {
+		return c.(registered).Core
+	}
+	return jsonAPI{}
+}
+
+// Use makes c the codec of the JSON bindings and renders, nil restores Default.
+func Use(c Core) {
+	current.Store(registered{c})
+}
+
+// Core is the interface of a JSON codec, matching the functions of encoding/json.
+type Core interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+	MarshalIndent(v any, prefix, indent string) ([]byte, error)
+	NewEncoder(w io.Writer) Encoder
+	NewDecoder(r io.Reader) Decoder
+}
+
+// Encoder is the interface of a JSON encoder, matching *json.Encoder.
+type Encoder interface {
+	// SetEscapeHTML specifies whether problematic HTML characters should be escaped
+	// inside JSON quoted strings.
+	SetEscapeHTML(on bool)
+	// SetIndent makes Encode indent each encoded value.
+	SetIndent(prefix, indent string)
+	// Encode writes the JSON encoding of v to the stream, followed by a newline.
+	Encode(v any) error
+}
+
+// Decoder is the interface of a JSON decoder, matching *json.Decoder.
+type Decoder interface {
+	// UseNumber makes Decode unmarshal numbers into an interface{} as a Number
+	// instead of as a float64.
+	UseNumber()
+	// DisallowUnknownFields makes Decode return an error when the destination is a
+	// struct and the input contains object keys which do not match any field.
+	DisallowUnknownFields()
+	// Decode reads the next JSON-encoded value from its input and stores it in v.
+	Decode(v any) error
+}
@@ -0,0 +1,36 @@
+//go:build !go_json
+
+package json
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// The errors of the codec reporting an offset in the input.
+type (
+	SyntaxError        = json.SyntaxError
+	UnmarshalTypeError = json.UnmarshalTypeError
+)
+
+type jsonAPI struct{}
+
+func (jsonAPI) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonAPI) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonAPI) MarshalIndent(v any, prefix, indent string) ([]byte, error) {
+	return json.MarshalIndent(v, prefix, indent)
+}
+
+func (jsonAPI) NewEncoder(w io.Writer) Encoder {
+	return json.NewEncoder(w)
+}
+
+func (jsonAPI) NewDecoder(r io.Reader) Decoder {
+	return json.NewDecoder(r)
+}
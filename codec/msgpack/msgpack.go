@@ -0,0 +1,14 @@
+// Package msgpack holds the MessagePack codec handle shared by the MsgPack binding and render.
+package msgpack
+
+import "github.com/ugorji/go/codec"
+
+// Handle is the codec handle of the MsgPack binding and render. Structs are encoded as maps
+// and the "codec" and "json" struct tags are honoured, so types bound from JSON can be bound
+// from MessagePack unchanged.
+var Handle = func() *codec.MsgpackHandle {
+	h := &codec.MsgpackHandle{WriteExt: true}
+	h.RawToString = true
+	h.TypeInfos = codec.NewTypeInfos([]string{"codec", "json"})
+	return h
+}()
@@ -1,13 +1,17 @@
 package dawn
 
 import (
+	"bytes"
 	"dawn/binding"
 	"dawn/render"
+	"errors"
 	"io"
 	"math"
 	"mime/multipart"
 	"net/http"
 	"net/url"
+	"os"
+	"strings"
 	"sync"
 	"time"
 )
@@ -15,27 +19,37 @@ import (
 // Content-Type MIME of the most common data formats.
 const (
 	MIMEJSON              = binding.MIMEJSON
-	MIMEHTML              = binding.MIMEJSON
-	MIMEXML               = binding.MIMEJSON
-	MIMEXML2              = binding.MIMEJSON
-	MIMEPlain             = binding.MIMEJSON
-	MIMEPOSTForm          = binding.MIMEJSON
-	MIMEMultipartPOSTForm = binding.MIMEJSON
-	MIMEPROTOBUF          = binding.MIMEJSON
-	MIMEMSGPACK           = binding.MIMEJSON
-	MIMEMSGPACK2          = binding.MIMEJSON
-	MIMEYAML              = binding.MIMEJSON
-	MIMETOML              = binding.MIMEJSON
+	MIMENDJSON            = binding.MIMENDJSON
+	MIMEHTML              = binding.MIMEHTML
+	MIMEXML               = binding.MIMEXML
+	MIMEXML2              = binding.MIMEXML2
+	MIMEPlain             = binding.MIMEPlain
+	MIMEPOSTForm          = binding.MIMEPOSTForm
+	MIMEMultipartPOSTForm = binding.MIMEMultipartPOSTForm
+	MIMEPROTOBUF          = binding.MIMEPROTOBUF
+	MIMEMSGPACK           = binding.MIMEMSGPACK
+	MIMEMSGPACK2          = binding.MIMEMSGPACK2
+	MIMECBOR              = binding.MIMECBOR
+	MIMEYAML              = binding.MIMEYAML
+	MIMETOML              = binding.MIMETOML
+	MIMEMergePatch        = binding.MIMEMergePatch
+	MIMEJSONPatch         = binding.MIMEJSONPatch
 )
 
 const (
 	// BodyBytesKey indicates a default body bytes key.
 	BodyBytesKey = "_dawn/bodybyteskey"
 
+	// BoundFieldsKey indicates the key of the field mask of a partial binding.
+	BoundFieldsKey = "_dawn/boundfieldskey"
+
 	// ContextKey is the key that a Context returns itself for.
 	ContextKey = "_dawn/contextkey"
 )
 
+// ErrNoHTMLRender is reported by HTML when the engine has no HTMLRender.
+var ErrNoHTMLRender = errors.New("dawn: no HTML render configured")
+
 // abortIndex represents a typical value used in abort functions.
 const abortIndex int8 = math.MaxInt8 >> 1
 
@@ -48,7 +62,7 @@ type Context struct {
 
 	Params   Params
 	handlers HandlersChain
-	index    string
+	index    int8
 	fullPath string
 
 	engine       *Engine
@@ -85,7 +99,41 @@ type Context struct {
 
 func (c *Context) reset() {}
 
-func (c *Context) Copy() {}
+// Copy returns a copy of the current context that can be safely used outside the request's scope.
+// This has to be used when the context has to be passed to a goroutine.
+// The response can not be written through the copy, its Writer reports it as written.
+func (c *Context) Copy() *Context {
+	cp := Context{
+		writermem: c.writermem,
+		Request:   c.Request,
+		Params:    c.Params,
+		engine:    c.engine,
+		sameSite:  c.sameSite,
+	}
+	cp.writermem.ResponseWriter = nil
+	cp.writermem.beforeWrite = nil
+	if !cp.writermem.Written() {
+		cp.writermem.size = 0
+	}
+	cp.Writer = &cp.writermem
+	cp.index = abortIndex
+	cp.handlers = nil
+
+	c.mu.RLock()
+	cp.Keys = make(map[string]any, len(c.Keys))
+	for k, v := range c.Keys {
+		cp.Keys[k] = v
+	}
+	c.mu.RUnlock()
+	if s, ok := cp.Keys[SessionKey].(*Session); ok {
+		cp.Keys[SessionKey] = s.bind(&cp)
+	}
+
+	paramCopy := make([]Param, len(cp.Params))
+	copy(paramCopy, cp.Params)
+	cp.Params = paramCopy
+	return &cp
+}
 
 func (c *Context) HandlerName() string {
 	return ""
@@ -107,38 +155,95 @@ func (c *Context) FullPath() string {
 /*********** FLOW CONTROL ***********/
 /************************************/
 
-func (c *Context) Next() {}
+// Next should be used only inside middleware.
+// It executes the pending handlers in the chain inside the calling handler.
+func (c *Context) Next() {
+	c.index++
+	for c.index < int8(len(c.handlers)) {
+		c.handlers[c.index](c)
+		c.index++
+	}
+}
 
+// IsAborted returns true if the current context was aborted.
 func (c *Context) IsAborted() bool {
-	return false
+	return c.index >= abortIndex
 }
 
-func (c *Context) Abort() {}
+// Abort prevents pending handlers from being called. Note that this will not stop the current handler.
+func (c *Context) Abort() {
+	c.index = abortIndex
+}
 
-func (c *Context) AbortWithStatus(code int) {}
+// AbortWithStatus calls `Abort()` and writes the headers with the specified status code.
+func (c *Context) AbortWithStatus(code int) {
+	c.Status(code)
+	c.Writer.WriteHeaderNow()
+	c.Abort()
+}
 
 func (c *Context) AbortWithStatusJSON(code int, jsonObj any) {}
 
+// AbortWithError calls `Abort()` and `Error()` internally.
+// This method stops the chain, sets the status code and pushes the specified error to `c.Errors`.
+// The headers are not written yet, so a later middleware, e.g. Problems, can still render the
+// error. See Context.Error() for more details.
 func (c *Context) AbortWithError(code int, err error) *Error {
-	return nil
+	c.Status(code)
+	c.Abort()
+	return c.Error(err)
 }
 
 /************************************/
 /********* ERROR MANAGEMENT *********/
 /************************************/
 
+// Error attaches an error to the current context. The error is pushed to a list of errors.
+// It's a good idea to call Error for each error that occurred during the resolution of a request.
+// A middleware can be used to collect all the errors and push them to a database together,
+// print a log, or append it in the HTTP response.
+// Error will panic if err is nil.
 func (c *Context) Error(err error) *Error {
-	return nil
+	if err == nil {
+		panic("err is nil")
+	}
+
+	var parsedError *Error
+	ok := errors.As(err, &parsedError)
+	if !ok {
+		parsedError = &Error{
+			Err:  err,
+			Type: ErrorTypePrivate,
+		}
+	}
+
+	c.Errors = append(c.Errors, parsedError)
+	return parsedError
 }
 
 /************************************/
 /******** METADATA MANAGEMENT********/
 /************************************/
 
-func (c *Context) Set(key string, value any) {}
+// Set is used to store a new key/value pair exclusively for this context.
+// It also lazy initializes  c.Keys if it was not used previously.
+func (c *Context) Set(key string, value any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Keys == nil {
+		c.Keys = make(map[string]any)
+	}
+
+	c.Keys[key] = value
+}
 
+// Get returns the value for the given key, ie: (value, true).
+// If the value does not exist it returns (nil, false)
 func (c *Context) Get(key string) (value any, exists bool) {
-	return nil, false
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	value, exists = c.Keys[key]
+	return
 }
 
 func (c *Context) MustGet(key string) (s string) {
@@ -207,26 +312,71 @@ func (c *Context) Param(key string) string {
 
 func (c *Context) AddParam(key, value string) {}
 
+// Query returns the keyed url query value if it exists, otherwise it returns an empty
+// string `("")`. It is shortcut for `c.Request.URL.Query().Get(key)`.
+//
+//	GET /path?id=1234&name=Manu&value=
+//	c.Query("id") == "1234"
+//	c.Query("name") == "Manu"
+//	c.Query("value") == ""
+//	c.Query("wtf") == ""
 func (c *Context) Query(key string) (value string) {
-	return ""
+	value, _ = c.GetQuery(key)
+	return
 }
 
+// DefaultQuery returns the keyed url query value if it exists, otherwise it returns the
+// specified defaultValue string. See: Query() and GetQuery() for further information.
+//
+//	GET /?name=Manu&lastname=
+//	c.DefaultQuery("name", "unknown") == "Manu"
+//	c.DefaultQuery("id", "none") == "none"
+//	c.DefaultQuery("lastname", "none") == ""
 func (c *Context) DefaultQuery(key, defaultValue string) string {
-	return ""
-}
-
+	if value, ok := c.GetQuery(key); ok {
+		return value
+	}
+	return defaultValue
+}
+
+// GetQuery is like Query(), it returns the keyed url query value if it exists
+// `(value, true)` (even when the value is an empty string), otherwise it returns `("", false)`.
+// It is shortcut for `c.Request.URL.Query().Get(key)`
+//
+//	GET /?name=Manu&lastname=
+//	("Manu", true) == c.GetQuery("name")
+//	("", false) == c.GetQuery("id")
+//	("", true) == c.GetQuery("lastname")
 func (c *Context) GetQuery(key string) (string, bool) {
+	if values, ok := c.GetQuerySlice(key); ok {
+		return values[0], ok
+	}
 	return "", false
 }
 
+// QuerySlice returns a slice of strings for a given query key.
+// The length of the slice depends on the number of params with the given key.
 func (c *Context) QuerySlice(key string) (values []string) {
-	return nil
+	values, _ = c.GetQuerySlice(key)
+	return
 }
 
-func (c *Context) initQueryCache() {}
+func (c *Context) initQueryCache() {
+	if c.queryCache == nil {
+		if c.Request != nil && c.Request.URL != nil {
+			c.queryCache = c.Request.URL.Query()
+		} else {
+			c.queryCache = url.Values{}
+		}
+	}
+}
 
+// GetQuerySlice returns a slice of strings for a given query key, plus
+// a boolean value whether at least one value exists for the given key.
 func (c *Context) GetQuerySlice(key string) (values []string, ok bool) {
-	return nil, false
+	c.initQueryCache()
+	values, ok = c.queryCache[key]
+	return
 }
 
 func (c *Context) QueryMap(key string) (dicts map[string]string) {
@@ -283,82 +433,294 @@ func (c *Context) SaveUploadedFile(file *multipart.FileHeader, dst string) error
 	return nil
 }
 
+// Bind checks the Method and Content-Type to select a binding engine automatically,
+// Depending on the "Content-Type" header different bindings are used, for example:
+//
+//	"application/json" --> JSON binding
+//	"application/xml"  --> XML binding
+//
+// It parses the request's body as JSON if Content-Type == "application/json" using JSON or XML as a JSON input.
+// It decodes the json payload into the struct specified as a pointer.
+// It writes a 400 error and sets Content-Type header "text/plain" in the response if input is not valid.
 func (c *Context) Bind(obj any) error {
-	return nil
+	b := binding.Default(c.Request.Method, c.ContentType())
+	return c.MustBindWith(obj, b)
 }
 
+// BindJSON is a shortcut for c.MustBindWith(obj, binding.JSON).
 func (c *Context) BindJSON(obj any) error {
-	return nil
+	return c.MustBindWith(obj, binding.JSON)
 }
 
+// BindXML is a shortcut for c.MustBindWith(obj, binding.BindXML).
 func (c *Context) BindXML(obj any) error {
-	return nil
+	return c.MustBindWith(obj, binding.XML)
 }
 
+// BindQuery is a shortcut for c.MustBindWith(obj, binding.Query).
 func (c *Context) BindQuery(obj any) error {
-	return nil
+	return c.MustBindWith(obj, binding.Query)
 }
 
+// BindYAML is a shortcut for c.MustBindWith(obj, binding.YAML).
 func (c *Context) BindYAML(obj any) error {
-	return nil
+	return c.MustBindWith(obj, binding.YAML)
+}
+
+// BindMsgPack is a shortcut for c.MustBindWith(obj, binding.MsgPack).
+func (c *Context) BindMsgPack(obj any) error {
+	return c.MustBindWith(obj, binding.MsgPack)
 }
 
+// BindCBOR is a shortcut for c.MustBindWith(obj, binding.CBOR).
+func (c *Context) BindCBOR(obj any) error {
+	return c.MustBindWith(obj, binding.CBOR)
+}
+
+// BindTOML is a shortcut for c.MustBindWith(obj, binding.TOML).
 func (c *Context) BindTOML(obj any) error {
-	return nil
+	return c.MustBindWith(obj, binding.TOML)
 }
 
+// BindHeader is a shortcut for c.MustBindWith(obj, binding.Header).
 func (c *Context) BindHeader(obj any) error {
-	return nil
+	return c.MustBindWith(obj, binding.Header)
 }
 
+// BindUri binds the passed struct pointer using binding.Uri.
+// It will abort the request with HTTP 400 if any error occurs.
 func (c *Context) BindUri(obj any) error {
+	if err := c.ShouldBindUri(obj); err != nil {
+		c.AbortWithError(http.StatusBadRequest, err).SetType(ErrorTypeBind)
+		return err
+	}
 	return nil
 }
 
+// BindAll binds the passed struct pointer using binding.All.
+// It will abort the request with HTTP 400 if any error occurs, see MustBindWith.
+func (c *Context) BindAll(obj any) error {
+	if err := c.ShouldBindAll(obj); err != nil {
+		c.AbortWithError(bindErrorStatus(err), err).SetType(ErrorTypeBind)
+		return err
+	}
+	return nil
+}
+
+// MustBindWith binds the passed struct pointer using the specified binding engine.
+// It will abort the request with HTTP 400 if any error occurs, or with HTTP 413 if the
+// body is larger than allowed by http.MaxBytesReader. See the binding package.
 func (c *Context) MustBindWith(obj any, b binding.Binding) error {
+	if err := c.ShouldBindWith(obj, b); err != nil {
+		c.AbortWithError(bindErrorStatus(err), err).SetType(ErrorTypeBind)
+		return err
+	}
 	return nil
 }
 
+// bindErrorStatus returns the status of a request failing to bind with err.
+func bindErrorStatus(err error) int {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) || errors.Is(err, binding.ErrStreamItemTooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+// ShouldBind checks the Method and Content-Type to select a binding engine automatically,
+// Depending on the "Content-Type" header different bindings are used, for example:
+//
+//	"application/json" --> JSON binding
+//	"application/xml"  --> XML binding
+//
+// It parses the request's body as JSON if Content-Type == "application/json" using JSON or XML as a JSON input.
+// It decodes the json payload into the struct specified as a pointer.
+// Like c.Bind() but this method does not set the response status code to 400 or abort if input is not valid.
 func (c *Context) ShouldBind(obj any) error {
-	return nil
+	b := binding.Default(c.Request.Method, c.ContentType())
+	return c.ShouldBindWith(obj, b)
 }
 
+// ShouldBindJSON is a shortcut for c.ShouldBindWith(obj, binding.JSON).
 func (c *Context) ShouldBindJSON(obj any) error {
-	return nil
+	return c.ShouldBindWith(obj, binding.JSON)
 }
 
+// ShouldBindXML is a shortcut for c.ShouldBindWith(obj, binding.XML).
 func (c *Context) ShouldBindXML(obj any) error {
-	return nil
+	return c.ShouldBindWith(obj, binding.XML)
 }
 
+// ShouldBindQuery is a shortcut for c.ShouldBindWith(obj, binding.Query).
 func (c *Context) ShouldBindQuery(obj any) error {
-	return nil
+	return c.ShouldBindWith(obj, binding.Query)
 }
 
+// ShouldBindYAML is a shortcut for c.ShouldBindWith(obj, binding.YAML).
 func (c *Context) ShouldBindYAML(obj any) error {
-	return nil
+	return c.ShouldBindWith(obj, binding.YAML)
+}
+
+// ShouldBindMsgPack is a shortcut for c.ShouldBindWith(obj, binding.MsgPack).
+func (c *Context) ShouldBindMsgPack(obj any) error {
+	return c.ShouldBindWith(obj, binding.MsgPack)
+}
+
+// ShouldBindCBOR is a shortcut for c.ShouldBindWith(obj, binding.CBOR).
+func (c *Context) ShouldBindCBOR(obj any) error {
+	return c.ShouldBindWith(obj, binding.CBOR)
 }
 
+// ShouldBindTOML is a shortcut for c.ShouldBindWith(obj, binding.TOML).
 func (c *Context) ShouldBindTOML(obj any) error {
-	return nil
+	return c.ShouldBindWith(obj, binding.TOML)
 }
 
+// ShouldBindHeader is a shortcut for c.ShouldBindWith(obj, binding.Header).
 func (c *Context) ShouldBindHeader(obj any) error {
-	return nil
+	return c.ShouldBindWith(obj, binding.Header)
 }
 
+// ShouldBindUri binds the passed struct pointer using the specified binding engine.
 func (c *Context) ShouldBindUri(obj any) error {
-	return nil
+	return binding.Uri.Bind(c.paramsMap(), obj)
 }
 
+// ShouldBindAll binds the passed struct pointer from the path params, the query, the headers,
+// the cookies and the body at once, and validates it once. See binding.All.
+func (c *Context) ShouldBindAll(obj any) error {
+	return binding.All.Bind(c.Request, c.paramsMap(), obj)
+}
+
+func (c *Context) paramsMap() map[string][]string {
+	m := make(map[string][]string, len(c.Params))
+	for _, v := range c.Params {
+		m[v.Key] = []string{v.Value}
+	}
+	return m
+}
+
+// ShouldBindWith binds the passed struct pointer using the specified binding engine.
+// See the binding package.
 func (c *Context) ShouldBindWith(obj any, b binding.Binding) error {
+	return b.Bind(c.Request, obj)
+}
+
+// ShouldBindBodyWith is similar with ShouldBindWith, but it stores the request
+// body into the context, and reuse when it is called again.
+//
+// NOTE: This method reads the body before binding. So you should use
+// ShouldBindWith for better performance if you need to call only once.
+func (c *Context) ShouldBindBodyWith(obj any, bb binding.BindingBody) (err error) {
+	var body []byte
+	if cb, ok := c.Get(BodyBytesKey); ok {
+		if cbb, ok := cb.([]byte); ok {
+			body = cbb
+		}
+	}
+	if body == nil {
+		body, err = io.ReadAll(c.Request.Body)
+		if err != nil {
+			return err
+		}
+		c.Set(BodyBytesKey, body)
+	}
+	return bb.BindBody(body, obj)
+}
+
+// BindPartial binds the passed struct pointer for a partial update such as a PATCH request,
+// see ShouldBindPartial. It will abort the request with HTTP 400 if any error occurs, see
+// MustBindWith.
+func (c *Context) BindPartial(obj any) error {
+	if err := c.ShouldBindPartial(obj); err != nil {
+		c.AbortWithError(bindErrorStatus(err), err).SetType(ErrorTypeBind)
+		return err
+	}
 	return nil
 }
 
-func (c *Context) ShouldBindBodyWith(obj any, bb binding.BindingBody) error {
+// ShouldBindPartial binds the passed struct pointer with the JSON or form binding selected by
+// the Content-Type, setting and validating only the fields present in the input, without
+// their required rules. The fields present are then returned by BoundFields:
+//
+//	var patch User
+//	if err := c.ShouldBindPartial(&patch); err != nil {
+//		...
+//	}
+//	if c.BoundFields().Has("email") {
+//		user.Email = patch.Email
+//	}
+//
+// See binding.BindPartial.
+func (c *Context) ShouldBindPartial(obj any) error {
+	b := binding.Default(c.Request.Method, c.ContentType())
+	mask, err := binding.BindPartial(c.Request, obj, b)
+	if mask != nil {
+		c.Set(BoundFieldsKey, mask)
+	}
+	return err
+}
+
+// BoundFields returns the paths of the fields present in the input of the last partial
+// binding, e.g. "name" or "address.city", or nil if there was none.
+func (c *Context) BoundFields() binding.FieldMask {
+	if mask, ok := c.Get(BoundFieldsKey); ok {
+		if mask, ok := mask.(binding.FieldMask); ok {
+			return mask
+		}
+	}
 	return nil
 }
 
+// ValidationErrors returns the validation errors of err, an error of the bindings, with their
+// messages in the language of the Accept-Language header of the request, or nil if err is no
+// validation error. The result is serialisable as JSON:
+//
+//	if err := c.ShouldBindJSON(&user); err != nil {
+//		c.JSON(http.StatusBadRequest, H{"errors": c.ValidationErrors(err)})
+//		return
+//	}
+func (c *Context) ValidationErrors(err error) binding.ValidationErrors {
+	errs, ok := binding.AsValidationErrors(err)
+	if !ok {
+		return nil
+	}
+	return errs.Translate(c.acceptLanguages()...)
+}
+
+// acceptLanguages returns the languages of the Accept-Language header, by preference.
+func (c *Context) acceptLanguages() []string {
+	return parseAcceptLanguage(c.requestHeader("Accept-Language"))
+}
+
+// BindStream calls fn with each item of the request body, an "application/x-ndjson" stream
+// or an "application/json" array, decoded and validated one at a time, see binding.Stream.
+// Methods can not have type parameters, so it takes the Context:
+//
+//	err := dawn.BindStream(c, func(order *Order) error {
+//		return store.Insert(order)
+//	})
+//
+// It will abort the request with HTTP 400 if an item is invalid, or with HTTP 413 if an item or
+// the body is too large. The errors of fn are returned as is, without aborting.
+func BindStream[T any](c *Context, fn func(item *T) error) error {
+	fnFailed := false
+	err := ShouldBindStream(c, func(item *T) error {
+		err := fn(item)
+		fnFailed = err != nil
+		return err
+	})
+	if err != nil && !fnFailed {
+		c.AbortWithError(bindErrorStatus(err), err).SetType(ErrorTypeBind)
+	}
+	return err
+}
+
+// ShouldBindStream is like BindStream, but does not abort the request if an item is invalid.
+func ShouldBindStream[T any](c *Context, fn func(item *T) error) error {
+	return binding.Stream(c.Request, fn)
+}
+
 func (c *Context) ClientIP() string {
 	return ""
 }
@@ -367,91 +729,491 @@ func (c *Context) RemoteIP() string {
 	return ""
 }
 
+// ContentType returns the Content-Type header of the request.
 func (c *Context) ContentType() string {
-	return ""
+	return filterFlags(c.requestHeader("Content-Type"))
 }
 
+// IsWebsocket returns true if the request headers indicate that a websocket
+// handshake is being initiated by the client.
 func (c *Context) IsWebsocket() bool {
+	if strings.Contains(strings.ToLower(c.requestHeader("Connection")), "upgrade") &&
+		strings.EqualFold(c.requestHeader("Upgrade"), "websocket") {
+		return true
+	}
 	return false
 }
 
 func (c *Context) requestHeader(key string) string {
-	return ""
+	return c.Request.Header.Get(key)
 }
 
 /************************************/
 /******** RESPONSE RENDERING ********/
 /************************************/
 
+// bodyAllowedForStatus is a copy of http.bodyAllowedForStatus non-exported function.
 func bodyAllowedForStatus(status int) bool {
-	return false
-}
-
-func (c *Context) Status(code int) {}
-
-func (c *Context) Header(key, value string) {}
-
+	switch {
+	case status >= 100 && status <= 199:
+		return false
+	case status == http.StatusNoContent:
+		return false
+	case status == http.StatusNotModified:
+		return false
+	}
+	return true
+}
+
+// Status sets the HTTP response code.
+func (c *Context) Status(code int) {
+	c.Writer.WriteHeader(code)
+}
+
+// Header is an intelligent shortcut for c.Writer.Header().Set(key, value).
+// It writes a header in the response.
+// If value == "", this method removes the header `c.Writer.Header().Del(key)`
+func (c *Context) Header(key, value string) {
+	if value == "" {
+		c.Writer.Header().Del(key)
+		return
+	}
+	c.Writer.Header().Set(key, value)
+}
+
+// GetHeader returns value from request headers.
 func (c *Context) GetHeader(key string) string {
-	return ""
+	return c.requestHeader(key)
 }
 
+// GetRawData returns stream data.
 func (c *Context) GetRawData() ([]byte, error) {
-	return nil, nil
+	return io.ReadAll(c.Request.Body)
 }
 
-func (c *Context) SetSameSite(samesite http.SameSite) {}
-
-func (c *Context) SetCookie(name, value string, maxAge int, path, domain string, secure, httpOnly bool) {
-
+// SetSameSite with cookie, it is used by SetCookie and SetCookieData when the cookie has no
+// SameSite mode of its own.
+func (c *Context) SetSameSite(samesite http.SameSite) {
+	c.sameSite = samesite
 }
 
+// SetCookie adds a Set-Cookie header to the ResponseWriter's headers.
+// The provided cookie must have a valid Name. Invalid cookies may be
+// silently dropped.
+func (c *Context) SetCookie(name, value string, maxAge int, path, domain string, secure, httpOnly bool) {
+	c.SetCookieData(&http.Cookie{
+		Name:     name,
+		Value:    url.QueryEscape(value),
+		MaxAge:   maxAge,
+		Path:     path,
+		Domain:   domain,
+		Secure:   secure,
+		HttpOnly: httpOnly,
+	})
+}
+
+// SetCookieData adds a Set-Cookie header with all the http.Cookie options, such as SameSite
+// and Partitioned, to the ResponseWriter's headers. The value is written as is.
+func (c *Context) SetCookieData(cookie *http.Cookie) {
+	c.cookieDefaults(cookie)
+	http.SetCookie(c.Writer, cookie)
+}
+
+// cookieDefaults sets the path and SameSite attribute of cookie when they are unset.
+func (c *Context) cookieDefaults(cookie *http.Cookie) {
+	if cookie.Path == "" {
+		cookie.Path = "/"
+	}
+	if cookie.SameSite == 0 {
+		cookie.SameSite = c.sameSite
+	}
+}
+
+// Cookie returns the named cookie provided in the request or
+// ErrNoCookie if not found. And return the named cookie is unescaped.
+// If multiple cookies match the given name, only one cookie will
+// be returned.
 func (c *Context) Cookie(name string) (string, error) {
-	return "", nil
+	cookie, err := c.Request.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	val, _ := url.QueryUnescape(cookie.Value)
+	return val, nil
+}
+
+// SetSignedCookie sets the cookie with its value signed by HMAC-SHA256 using the newest key of
+// Engine.CookieKeyring. The value stays readable by the client but can not be altered, its
+// max-age is also enforced when reading it back.
+func (c *Context) SetSignedCookie(cookie *http.Cookie) error {
+	signed, err := c.signCookie(cookie)
+	if err != nil {
+		return err
+	}
+	c.SetCookieData(signed)
+	return nil
 }
 
-func (c *Context) Render(code int, r render.Render) {}
+// signCookie returns a copy of cookie with its value signed, as set by SetSignedCookie.
+func (c *Context) signCookie(cookie *http.Cookie) (*http.Cookie, error) {
+	keyring, err := c.cookieKeyring()
+	if err != nil {
+		return nil, err
+	}
+	signed := *cookie
+	signed.Value = keyring.sign(cookie.Name, cookie.Value, cookieExpires(cookie))
+	return &signed, nil
+}
+
+// SignedCookie returns the value of the named cookie set by SetSignedCookie. It returns
+// ErrInvalidCookie if no key of Engine.CookieKeyring verifies it and ErrCookieExpired if it is
+// past its max-age.
+func (c *Context) SignedCookie(name string) (string, error) {
+	keyring, err := c.cookieKeyring()
+	if err != nil {
+		return "", err
+	}
+	cookie, err := c.Request.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	return keyring.verify(name, cookie.Value, time.Now())
+}
+
+// SetEncryptedCookie sets the cookie with its value encrypted by AES-GCM using the newest key
+// of Engine.CookieKeyring, so the client can neither read nor alter it.
+func (c *Context) SetEncryptedCookie(cookie *http.Cookie) error {
+	keyring, err := c.cookieKeyring()
+	if err != nil {
+		return err
+	}
+	encrypted := *cookie
+	if encrypted.Value, err = keyring.encrypt(cookie.Name, cookie.Value, cookieExpires(cookie)); err != nil {
+		return err
+	}
+	c.SetCookieData(&encrypted)
+	return nil
+}
 
-func (c *Context) HTML(code int, name string, obj any) {}
+// EncryptedCookie returns the decrypted value of the named cookie set by SetEncryptedCookie.
+// It returns ErrInvalidCookie if no key of Engine.CookieKeyring decrypts it and
+// ErrCookieExpired if it is past its max-age.
+func (c *Context) EncryptedCookie(name string) (string, error) {
+	keyring, err := c.cookieKeyring()
+	if err != nil {
+		return "", err
+	}
+	cookie, err := c.Request.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	return keyring.decrypt(name, cookie.Value, time.Now())
+}
+
+func (c *Context) cookieKeyring() (*Keyring, error) {
+	if c.engine == nil || c.engine.CookieKeyring == nil {
+		return nil, ErrNoKeyring
+	}
+	return c.engine.CookieKeyring, nil
+}
+
+// cookieExpires returns the unix time after which the server rejects the cookie, zero for a
+// session cookie.
+func cookieExpires(cookie *http.Cookie) int64 {
+	switch {
+	case cookie.MaxAge > 0:
+		return time.Now().Unix() + int64(cookie.MaxAge)
+	case !cookie.Expires.IsZero():
+		return cookie.Expires.Unix()
+	}
+	return 0
+}
 
-func (c *Context) IndentedJSON(code int, obj any) {}
+// Render writes the response headers and calls render.Render to render data.
+func (c *Context) Render(code int, r render.Render) {
+	c.Status(code)
 
-func (c *Context) SecureJSON(code int, obj any) {}
+	if !bodyAllowedForStatus(code) {
+		r.WriteContentType(c.Writer)
+		c.Writer.WriteHeaderNow()
+		return
+	}
 
-func (c *Context) JSONP(code int, obj any) {}
+	if err := r.Render(c.Writer); err != nil {
+		_ = c.Error(err).SetType(ErrorTypeRender)
+		c.Abort()
+	}
+}
 
-func (c *Context) JSON(code int, obj any) {}
+// HTML renders the HTTP template specified by its file name with the HTMLRender of the engine.
+// It also updates the HTTP code and sets the Content-Type as "text/html".
+// It aborts with 500 and ErrNoHTMLRender if the engine has no HTMLRender.
+func (c *Context) HTML(code int, name string, obj any) {
+	if c.engine == nil || c.engine.HTMLRender == nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, ErrNoHTMLRender).SetType(ErrorTypeRender)
+		return
+	}
+	instance := c.engine.HTMLRender.Instance(name, obj)
+	c.Render(code, instance)
+}
 
-func (c *Context) AsciiJSON(code int, obj any) {}
+// IndentedJSON serializes the given struct as pretty JSON (indented + endlines) into the response body.
+// It also sets the Content-Type as "application/json".
+// WARNING: we recommend using this only for development purposes since printing pretty JSON is
+// more CPU and bandwidth consuming. Use Context.JSON() instead.
+func (c *Context) IndentedJSON(code int, obj any) {
+	c.Render(code, render.IndentedJSON{Data: obj})
+}
 
-func (c *Context) PureJSON(code int, obj any) {}
+// SecureJSON serializes the given struct as Secure JSON into the response body.
+// Default prepends "while(1);" to response body if the given struct is array values.
+// It also sets the Content-Type as "application/json".
+func (c *Context) SecureJSON(code int, obj any) {
+	c.Render(code, render.SecureJSON{Prefix: c.engine.secureJSONPrefix, Data: obj})
+}
 
-func (c *Context) XML(code int, obj any) {}
+// JSONP serializes the given struct as JSON into the response body.
+// It adds padding to response body to request data from a server residing in a different domain than the client.
+// It also sets the Content-Type as "application/javascript".
+func (c *Context) JSONP(code int, obj any) {
+	callback := c.DefaultQuery("callback", "")
+	if callback == "" {
+		c.Render(code, render.JSON{Data: obj})
+		return
+	}
+	c.Render(code, render.JsonpJSON{Callback: callback, Data: obj})
+}
 
-func (c *Context) YAML(code int, obj any) {}
+// JSON serializes the given struct as JSON into the response body.
+// It also sets the Content-Type as "application/json".
+func (c *Context) JSON(code int, obj any) {
+	c.Render(code, render.JSON{Data: obj})
+}
 
-func (c *Context) TOML(code int, obj any) {}
+// AsciiJSON serializes the given struct as JSON into the response body with unicode to ASCII string.
+// It also sets the Content-Type as "application/json".
+func (c *Context) AsciiJSON(code int, obj any) {
+	c.Render(code, render.AsciiJSON{Data: obj})
+}
 
-func (c *Context) ProtoBuf(code int, obj any) {}
+// PureJSON serializes the given struct as JSON into the response body.
+// PureJSON, unlike JSON, does not replace special html characters with their unicode entities.
+func (c *Context) PureJSON(code int, obj any) {
+	c.Render(code, render.PureJSON{Data: obj})
+}
 
-func (c *Context) String(code int, obj any) {}
+// XML serializes the given struct as XML into the response body.
+// It also sets the Content-Type as "application/xml".
+func (c *Context) XML(code int, obj any) {
+	c.Render(code, render.XML{Data: obj})
+}
 
-func (c *Context) Redirect(code int, location string) {}
+// YAML serializes the given struct as YAML into the response body.
+func (c *Context) YAML(code int, obj any) {
+	c.Render(code, render.YAML{Data: obj})
+}
 
-func (c *Context) Data(code int, contentType string, data []byte) {}
+// TOML serializes the given struct as TOML into the response body.
+func (c *Context) TOML(code int, obj any) {
+	c.Render(code, render.TOML{Data: obj})
+}
 
-func (c *Context) DataFromRender(code int, contentLength int64, contentType string, render io.Reader, extraHeaders map[string]string) {
+// ProtoBuf serializes the given struct as ProtoBuf into the response body.
+func (c *Context) ProtoBuf(code int, obj any) {
+	c.Render(code, render.ProtoBuf{Data: obj})
 }
 
-func (c *Context) File(filePath string) {}
+// MsgPack serializes the given struct as MessagePack into the response body.
+func (c *Context) MsgPack(code int, obj any) {
+	c.Render(code, render.MsgPack{Data: obj})
+}
 
-func (c *Context) FileFromFS(filePath string, fs http.FileSystem) {}
+// CBOR serializes the given struct as CBOR into the response body.
+// Use c.Render(code, render.CBOR{Data: obj, Canonical: true}) for the deterministic encoding.
+func (c *Context) CBOR(code int, obj any) {
+	c.Render(code, render.CBOR{Data: obj})
+}
 
-func (c *Context) FileAttachment(filePath, fileName string) {}
+func (c *Context) String(code int, obj any) {}
 
-func (c *Context) SSEvent(name string, message any) {}
+func (c *Context) Redirect(code int, location string) {}
 
+// Data writes some data into the body stream and updates the HTTP code.
+// A successful GET or HEAD response gets an ETag generated from data, and conditional and
+// Range requests are answered with 304, 412 or 206 accordingly.
+func (c *Context) Data(code int, contentType string, data []byte) {
+	if c.servesContent(code) {
+		header := c.Writer.Header()
+		if header.Get("Content-Type") == "" {
+			header.Set("Content-Type", contentType)
+		}
+		if header.Get("ETag") == "" {
+			header.Set("ETag", dataETag(data))
+		}
+		http.ServeContent(c.Writer, c.Request, "", time.Time{}, bytes.NewReader(data))
+		return
+	}
+
+	c.Render(code, render.Data{
+		ContentType: contentType,
+		Data:        data,
+	})
+}
+
+// DataFromRender writes the specified reader into the body stream and updates the HTTP code.
+// If reader is an io.ReadSeeker, a successful GET or HEAD response supports conditional and
+// Range requests, validated by the ETag and Last-Modified headers given in extraHeaders.
+func (c *Context) DataFromRender(code int, contentLength int64, contentType string, reader io.Reader, extraHeaders map[string]string) {
+	if content, ok := reader.(io.ReadSeeker); ok && c.servesContent(code) {
+		header := c.Writer.Header()
+		for k, v := range extraHeaders {
+			if header.Get(k) == "" {
+				header.Set(k, v)
+			}
+		}
+		if header.Get("Content-Type") == "" {
+			header.Set("Content-Type", contentType)
+		}
+
+		var modtime time.Time
+		if lastModified := header.Get("Last-Modified"); lastModified != "" {
+			modtime, _ = http.ParseTime(lastModified)
+		}
+		http.ServeContent(c.Writer, c.Request, "", modtime, content)
+		return
+	}
+
+	c.Render(code, render.Reader{
+		Headers:       extraHeaders,
+		ContentType:   contentType,
+		ContentLength: contentLength,
+		Reader:        reader,
+	})
+}
+
+// servesContent reports whether a response with code may be answered as a partial or
+// not modified response.
+func (c *Context) servesContent(code int) bool {
+	return code == http.StatusOK &&
+		(c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead)
+}
+
+// File writes the specified file into the body stream in an efficient way.
+// It sets a weak ETag and Last-Modified, and handles conditional and Range requests.
+func (c *Context) File(filePath string) {
+	if fi, err := os.Stat(filePath); err == nil {
+		c.setFileETag(fi)
+	}
+	http.ServeFile(c.Writer, c.Request, filePath)
+}
+
+// FileFromFS writes the specified file from http.FileSystem into the body stream in an efficient way.
+// It sets a weak ETag and Last-Modified, and handles conditional and Range requests.
+func (c *Context) FileFromFS(filePath string, fs http.FileSystem) {
+	defer func(old string) {
+		c.Request.URL.Path = old
+	}(c.Request.URL.Path)
+
+	if f, err := fs.Open(filePath); err == nil {
+		if fi, err := f.Stat(); err == nil {
+			c.setFileETag(fi)
+		}
+		f.Close()
+	}
+
+	c.Request.URL.Path = filePath
+
+	http.FileServer(fs).ServeHTTP(c.Writer, c.Request)
+}
+
+func (c *Context) setFileETag(fi os.FileInfo) {
+	if fi.Mode().IsRegular() && c.Writer.Header().Get("ETag") == "" {
+		c.Writer.Header().Set("ETag", fileETag(fi))
+	}
+}
+
+// FileAttachment writes the specified file into the body stream in an efficient way
+// On the client side, the file will typically be downloaded with the given filename
+func (c *Context) FileAttachment(filePath, fileName string) {
+	if isASCII(fileName) {
+		c.Writer.Header().Set("Content-Disposition", `attachment; filename="`+escapeQuotes(fileName)+`"`)
+	} else {
+		c.Writer.Header().Set("Content-Disposition", `attachment; filename*=UTF-8''`+url.QueryEscape(fileName))
+	}
+	c.File(filePath)
+}
+
+// SSEvent writes a Server-Sent Event into the body stream. Use c.Render(-1, render.SSEvent{...})
+// to also set the event ID or the retry time.
+func (c *Context) SSEvent(name string, message any) {
+	c.Render(-1, render.SSEvent{
+		Event: name,
+		Data:  message,
+	})
+}
+
+// Stream sends a streaming response and returns a boolean
+// indicates "Is client disconnected in middle of stream"
 func (c *Context) Stream(step func(w io.Writer) bool) bool {
-	return false
+	w := c.Writer
+	clientGone := c.Request.Context().Done()
+	for {
+		select {
+		case <-clientGone:
+			return true
+		default:
+			keepOpen := step(w)
+			w.Flush()
+			if !keepOpen {
+				return false
+			}
+		}
+	}
+}
+
+// SSEventStream writes the events received from events as Server-Sent Events until the channel
+// is closed or the client disconnects, and returns true in the latter case. When keepAlive is
+// positive a comment is sent whenever no event was written for that long, so proxies do not
+// close the idle connection.
+func (c *Context) SSEventStream(events <-chan render.SSEvent, keepAlive time.Duration) bool {
+	var ticker *time.Ticker
+	var keepAliveC <-chan time.Time
+	if keepAlive > 0 {
+		ticker = time.NewTicker(keepAlive)
+		defer ticker.Stop()
+		keepAliveC = ticker.C
+	}
+
+	render.SSEvent{}.WriteContentType(c.Writer)
+	c.Writer.Flush()
+
+	clientGone := c.Request.Context().Done()
+	for {
+		select {
+		case <-clientGone:
+			return true
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.Render(-1, event)
+			if ticker != nil {
+				ticker.Reset(keepAlive)
+			}
+		case <-keepAliveC:
+			c.Render(-1, render.SSEvent{Comment: "keep-alive"})
+		}
+		if c.IsAborted() { // the write failed, the client is gone
+			return true
+		}
+		c.Writer.Flush()
+	}
+}
+
+// LastEventID returns the ID of the last event seen by a reconnecting EventSource client,
+// to be used with ReplayBuffer.Since.
+func (c *Context) LastEventID() string {
+	return c.requestHeader("Last-Event-ID")
 }
 
 /************************************/
@@ -460,38 +1222,130 @@ func (c *Context) Stream(step func(w io.Writer) bool) bool {
 
 // Negotiate contains all negotiations data.
 type Negotiate struct {
-	Offered  []string
-	HTMLName string
-	HTMLData any
-	JSONData any
-	XMLData  any
-	YAMLData any
-	Data     any
-	TOMLData any
-}
-
-func (c *Context) Negotiate(code int, config Negotiate) {}
-
+	Offered      []string
+	HTMLName     string
+	HTMLData     any
+	JSONData     any
+	XMLData      any
+	YAMLData     any
+	Data         any
+	TOMLData     any
+	ProtoBufData any
+	MsgPackData  any
+	CBORData     any
+}
+
+// Negotiate calls different Render according to acceptable Accept format.
+// If none of the offered formats is acceptable it aborts with 406 and lists the offered formats.
+func (c *Context) Negotiate(code int, config Negotiate) {
+	c.Writer.Header().Add("Vary", "Accept")
+
+	offer := c.NegotiateFormat(config.Offered...)
+	format := renderFormat(offer)
+	if format != "" && format != mediaType(offer) {
+		// a vendor type such as "application/vnd.acme+json" is rendered in the format of
+		// its suffix, with its own Content-Type.
+		c.Header("Content-Type", offer)
+	}
+
+	switch format {
+	case binding.MIMEJSON:
+		data := chooseData(config.JSONData, config.Data)
+		c.JSON(code, data)
+
+	case binding.MIMEHTML:
+		data := chooseData(config.HTMLData, config.Data)
+		c.HTML(code, config.HTMLName, data)
+
+	case binding.MIMEXML, binding.MIMEXML2:
+		data := chooseData(config.XMLData, config.Data)
+		c.XML(code, data)
+
+	case binding.MIMEYAML:
+		data := chooseData(config.YAMLData, config.Data)
+		c.YAML(code, data)
+
+	case binding.MIMETOML:
+		data := chooseData(config.TOMLData, config.Data)
+		c.TOML(code, data)
+
+	case binding.MIMEPROTOBUF:
+		data := chooseData(config.ProtoBufData, config.Data)
+		c.ProtoBuf(code, data)
+
+	case binding.MIMEMSGPACK, binding.MIMEMSGPACK2:
+		data := chooseData(config.MsgPackData, config.Data)
+		c.MsgPack(code, data)
+
+	case binding.MIMECBOR:
+		data := chooseData(config.CBORData, config.Data)
+		c.CBOR(code, data)
+
+	default:
+		_ = c.Error(errors.New("the accepted formats are not offered by the server"))
+		c.Abort()
+		c.Data(http.StatusNotAcceptable, binding.MIMEPlain,
+			[]byte("406 not acceptable, available: "+strings.Join(config.Offered, ", ")))
+	}
+}
+
+// NegotiateFormat returns the offered format that best matches the accepted formats, following
+// the q-values, wildcards and specificity rules of RFC 9110. Formats set by SetAccepted take
+// priority over the Accept header. It returns an empty string if nothing is acceptable.
 func (c *Context) NegotiateFormat(offered ...string) string {
-	return ""
+	assert1(len(offered) > 0, "you must provide at least one offer")
+
+	var accepted []mediaRange
+	if c.Accepted != nil {
+		for _, format := range c.Accepted {
+			if r, ok := parseMediaRange(format); ok {
+				accepted = append(accepted, r)
+			}
+		}
+	} else {
+		accepted = parseAccept(strings.Join(c.Request.Header.Values("Accept"), ","))
+	}
+	return negotiate(accepted, offered)
 }
 
-func (c *Context) SetAccepted(formats ...string) {}
+// SetAccepted sets Accept header data.
+func (c *Context) SetAccepted(formats ...string) {
+	c.Accepted = formats
+}
 
 /************************************/
 /***** GOLANG.ORG/X/NET/CONTEXT *****/
 /************************************/
 
+// hasRequestContext returns whether c.Request has Context and fallback.
+func (c *Context) hasRequestContext() bool {
+	hasFallback := c.engine != nil && c.engine.ContextWithFallback
+	hasRequestContext := c.Request != nil && c.Request.Context() != nil
+	return hasFallback && hasRequestContext
+}
+
+// Deadline returns that there is no deadline (ok==false) when c.Request has no Context.
 func (c *Context) Deadline() (deadline time.Time, ok bool) {
-	return time.Now(), false
+	if !c.hasRequestContext() {
+		return
+	}
+	return c.Request.Context().Deadline()
 }
 
+// Done returns nil (chan which will wait forever) when c.Request has no Context.
 func (c *Context) Done() <-chan struct{} {
-	return nil
+	if !c.hasRequestContext() {
+		return nil
+	}
+	return c.Request.Context().Done()
 }
 
+// Err returns nil when c.Request has no Context.
 func (c *Context) Err() error {
-	return nil
+	if !c.hasRequestContext() {
+		return nil
+	}
+	return c.Request.Context().Err()
 }
 
 func (c *Context) Value(key any) any {
@@ -0,0 +1,131 @@
+package dawn
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testLastModified = "Mon, 02 Jan 2006 15:04:05 GMT"
+
+func TestContextDataConditional(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	c, w := createTestContext(req)
+	c.Data(http.StatusOK, "text/plain", []byte("hello world"))
+	etag := w.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+	assert.Equal(t, "hello world", w.Body.String())
+
+	req.Header.Set("If-None-Match", etag)
+	c, w = createTestContext(req)
+	c.Data(http.StatusOK, "text/plain", []byte("hello world"))
+	c.Writer.WriteHeaderNow()
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("If-Match", `"other"`)
+	c, w = createTestContext(req)
+	c.Data(http.StatusOK, "text/plain", []byte("hello world"))
+	c.Writer.WriteHeaderNow()
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+}
+
+func TestContextDataRange(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Range", "bytes=6-")
+	c, w := createTestContext(req)
+	c.Data(http.StatusOK, "text/plain", []byte("hello world"))
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, "bytes 6-10/11", w.Header().Get("Content-Range"))
+	assert.Equal(t, "world", w.Body.String())
+
+	req.Header.Set("Range", "bytes=20-")
+	c, w = createTestContext(req)
+	c.Data(http.StatusOK, "text/plain", []byte("hello world"))
+	assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, w.Code)
+
+	// other responses than a successful GET are written as is.
+	req = httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("Range", "bytes=6-")
+	c, w = createTestContext(req)
+	c.Data(http.StatusCreated, "text/plain", []byte("hello world"))
+	c.Writer.WriteHeaderNow()
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "hello world", w.Body.String())
+	assert.Empty(t, w.Header().Get("ETag"))
+}
+
+func TestContextDataFromRenderRange(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Range", "bytes=0-4,6-")
+	c, w := createTestContext(req)
+	c.DataFromRender(http.StatusOK, 11, "text/plain", strings.NewReader("hello world"),
+		map[string]string{"Last-Modified": testLastModified})
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "multipart/byteranges")
+
+	// a stale If-Range sends the whole content.
+	req.Header.Set("If-Range", "Mon, 02 Jan 2000 15:04:05 GMT")
+	c, w = createTestContext(req)
+	c.DataFromRender(http.StatusOK, 11, "text/plain", strings.NewReader("hello world"),
+		map[string]string{"Last-Modified": testLastModified})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "hello world", w.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("If-Modified-Since", testLastModified)
+	c, w = createTestContext(req)
+	c.DataFromRender(http.StatusOK, 11, "text/plain", strings.NewReader("hello world"),
+		map[string]string{"Last-Modified": testLastModified})
+	c.Writer.WriteHeaderNow()
+	assert.Equal(t, http.StatusNotModified, w.Code)
+}
+
+func TestContextFileRange(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "file.txt")
+	require.NoError(t, os.WriteFile(name, []byte("abcdef"), 0o600))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Range", "bytes=1-2")
+	c, w := createTestContext(req)
+	c.File(name)
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, "bc", w.Body.String())
+	etag := w.Header().Get("ETag")
+	assert.True(t, strings.HasPrefix(etag, `W/"`))
+	assert.NotEmpty(t, w.Header().Get("Last-Modified"))
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("If-None-Match", etag)
+	c, w = createTestContext(req)
+	c.File(name)
+	c.Writer.WriteHeaderNow()
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	c, w = createTestContext(httptest.NewRequest(http.MethodGet, "/", nil))
+	c.FileFromFS("/file.txt", http.Dir(dir))
+	assert.Equal(t, "abcdef", w.Body.String())
+	assert.Equal(t, etag, w.Header().Get("ETag"))
+	assert.Equal(t, "/", c.Request.URL.Path)
+}
+
+func TestContextFileAttachment(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "file.txt")
+	require.NoError(t, os.WriteFile(name, []byte("abcdef"), 0o600))
+
+	c, w := createTestContext(httptest.NewRequest(http.MethodGet, "/", nil))
+	c.FileAttachment(name, `re"port.txt`)
+	assert.Equal(t, `attachment; filename="re\"port.txt"`, w.Header().Get("Content-Disposition"))
+
+	c, w = createTestContext(httptest.NewRequest(http.MethodGet, "/", nil))
+	c.FileAttachment(name, "ü.txt")
+	assert.Equal(t, "attachment; filename*=UTF-8''%C3%BC.txt", w.Header().Get("Content-Disposition"))
+}
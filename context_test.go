@@ -0,0 +1,84 @@
+package dawn
+
+import (
+	"dawn/binding"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextQuery(t *testing.T) {
+	c, _ := createTestContext(httptest.NewRequest(http.MethodGet, "/?foo=bar&page=10&id=&id=2", nil))
+
+	value, ok := c.GetQuery("foo")
+	assert.True(t, ok)
+	assert.Equal(t, "bar", value)
+	assert.Equal(t, "bar", c.Query("foo"))
+	assert.Equal(t, "bar", c.DefaultQuery("foo", "none"))
+	assert.Equal(t, "10", c.DefaultQuery("page", "0"))
+
+	value, ok = c.GetQuery("id")
+	assert.True(t, ok)
+	assert.Empty(t, value)
+	assert.Empty(t, c.DefaultQuery("id", "nada"))
+	assert.Equal(t, []string{"", "2"}, c.QuerySlice("id"))
+
+	value, ok = c.GetQuery("NoKey")
+	assert.False(t, ok)
+	assert.Empty(t, value)
+	assert.Equal(t, "nada", c.DefaultQuery("NoKey", "nada"))
+	assert.Empty(t, c.Query("NoKey"))
+	assert.Empty(t, c.QuerySlice("NoKey"))
+
+	c, _ = createTestContext(nil)
+	assert.Equal(t, "nada", c.DefaultQuery("foo", "nada"))
+}
+
+func TestContextJSONP(t *testing.T) {
+	c, w := createTestContext(httptest.NewRequest(http.MethodGet, "/?callback=x", nil))
+	c.JSONP(http.StatusCreated, H{"foo": "bar"})
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "x({\"foo\":\"bar\"});", w.Body.String())
+	assert.Equal(t, "application/javascript; charset=utf-8", w.Header().Get("Content-Type"))
+
+	// without a callback, plain JSON is rendered.
+	c, w = createTestContext(httptest.NewRequest(http.MethodGet, "/", nil))
+	c.JSONP(http.StatusOK, H{"foo": "bar"})
+	assert.Equal(t, "{\"foo\":\"bar\"}", w.Body.String())
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+}
+
+func TestBindStreamStatus(t *testing.T) {
+	defer func(max int64) { binding.MaxStreamItemBytes = max }(binding.MaxStreamItemBytes)
+	binding.MaxStreamItemBytes = 64
+
+	type item struct {
+		Name string `json:"name" binding:"required"`
+	}
+	for _, tt := range []struct {
+		body   string
+		status int
+	}{
+		{`[{"name":"a"},{"name":"b"}]`, http.StatusOK},
+		{`[{"name":"a"},{}]`, http.StatusBadRequest},
+		{`[{"name":"a"}] garbage`, http.StatusBadRequest},
+		{`[{"name":"` + strings.Repeat("x", 300) + `"}]`, http.StatusRequestEntityTooLarge},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", MIMEJSON)
+		c, w := createTestContext(req)
+		count := 0
+		err := BindStream(c, func(*item) error {
+			count++
+			return nil
+		})
+		c.Writer.WriteHeaderNow()
+		assert.Equal(t, tt.status, w.Code, tt.body)
+		assert.Equal(t, tt.status != http.StatusOK, err != nil, tt.body)
+		assert.Equal(t, tt.status != http.StatusOK, c.IsAborted(), tt.body)
+	}
+}
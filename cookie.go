@@ -0,0 +1,187 @@
+package dawn
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNoKeyring is returned by the signed and encrypted cookie methods when Engine.CookieKeyring is nil.
+	ErrNoKeyring = errors.New("dawn: no cookie keyring configured")
+
+	// ErrInvalidCookie is returned when a cookie fails verification or decryption with every key.
+	ErrInvalidCookie = errors.New("dawn: invalid cookie")
+
+	// ErrCookieExpired is returned when a valid cookie is past its max-age.
+	ErrCookieExpired = errors.New("dawn: cookie expired")
+)
+
+var cookieEncoding = base64.RawURLEncoding
+
+// Keyring holds the secret keys of signed and encrypted cookies. The newest key signs and
+// encrypts while all keys verify and decrypt, so keys can be rotated without invalidating the
+// cookies already issued. It is safe for concurrent use.
+type Keyring struct {
+	mu   sync.RWMutex
+	keys [][]byte
+}
+
+// NewKeyring returns a Keyring with the given keys, ordered from newest to oldest.
+func NewKeyring(keys ...[]byte) *Keyring {
+	assert1(len(keys) > 0, "keyring needs at least one key")
+	k := &Keyring{}
+	for _, key := range keys {
+		assert1(len(key) > 0, "keyring keys must not be empty")
+		k.keys = append(k.keys, key)
+	}
+	return k
+}
+
+// Rotate makes key the newest key, the previous keys are kept to verify existing cookies.
+func (k *Keyring) Rotate(key []byte) {
+	assert1(len(key) > 0, "keyring keys must not be empty")
+	k.mu.Lock()
+	k.keys = append([][]byte{key}, k.keys...)
+	k.mu.Unlock()
+}
+
+// Retire drops all but the n newest keys, cookies issued with a dropped key become invalid.
+func (k *Keyring) Retire(n int) {
+	assert1(n > 0, "keyring needs at least one key")
+	k.mu.Lock()
+	if len(k.keys) > n {
+		k.keys = k.keys[:n]
+	}
+	k.mu.Unlock()
+}
+
+func (k *Keyring) snapshot() [][]byte {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.keys
+}
+
+// derive returns a sub key of key for purpose, so signing and encryption never share keys.
+func derive(key []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+// sign returns "value|expires|mac" with every part base64 or decimal encoded. The cookie name
+// is part of the MAC so a value can not be moved to another cookie.
+func (k *Keyring) sign(name, value string, expires int64) string {
+	payload := cookieEncoding.EncodeToString([]byte(value)) + "|" + strconv.FormatInt(expires, 10)
+	mac := cookieMAC(k.snapshot()[0], name, payload)
+	return payload + "|" + cookieEncoding.EncodeToString(mac)
+}
+
+func (k *Keyring) verify(name, signed string, now time.Time) (string, error) {
+	i := strings.LastIndexByte(signed, '|')
+	if i < 0 {
+		return "", ErrInvalidCookie
+	}
+	payload := signed[:i]
+	mac, err := cookieEncoding.DecodeString(signed[i+1:])
+	if err != nil {
+		return "", ErrInvalidCookie
+	}
+
+	valid := false
+	for _, key := range k.snapshot() {
+		if hmac.Equal(mac, cookieMAC(key, name, payload)) {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return "", ErrInvalidCookie
+	}
+
+	encoded, expiresStr, _ := strings.Cut(payload, "|")
+	expires, err := strconv.ParseInt(expiresStr, 10, 64)
+	if err != nil {
+		return "", ErrInvalidCookie
+	}
+	if expires > 0 && now.Unix() >= expires {
+		return "", ErrCookieExpired
+	}
+	value, err := cookieEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", ErrInvalidCookie
+	}
+	return string(value), nil
+}
+
+func cookieMAC(key []byte, name, payload string) []byte {
+	mac := hmac.New(sha256.New, derive(key, "dawn signed cookie"))
+	mac.Write([]byte(name))
+	mac.Write([]byte{'|'})
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+// encrypt seals the expiry time and value with AES-GCM using the cookie name as additional
+// data, and returns base64(nonce|ciphertext).
+func (k *Keyring) encrypt(name, value string, expires int64) (string, error) {
+	aead, err := cookieAEAD(k.snapshot()[0])
+	if err != nil {
+		return "", err
+	}
+
+	plaintext := make([]byte, 8, 8+len(value))
+	binary.BigEndian.PutUint64(plaintext, uint64(expires))
+	plaintext = append(plaintext, value...)
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return cookieEncoding.EncodeToString(aead.Seal(nonce, nonce, plaintext, []byte(name))), nil
+}
+
+func (k *Keyring) decrypt(name, encrypted string, now time.Time) (string, error) {
+	data, err := cookieEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", ErrInvalidCookie
+	}
+
+	for _, key := range k.snapshot() {
+		aead, err := cookieAEAD(key)
+		if err != nil {
+			return "", err
+		}
+		if len(data) < aead.NonceSize() {
+			return "", ErrInvalidCookie
+		}
+		nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+		plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(name))
+		if err != nil || len(plaintext) < 8 {
+			continue
+		}
+
+		if expires := int64(binary.BigEndian.Uint64(plaintext)); expires > 0 && now.Unix() >= expires {
+			return "", ErrCookieExpired
+		}
+		return string(plaintext[8:]), nil
+	}
+	return "", ErrInvalidCookie
+}
+
+func cookieAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(derive(key, "dawn encrypted cookie"))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
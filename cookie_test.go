@@ -0,0 +1,118 @@
+package dawn
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cookieRequest returns a request carrying the cookies set in w.
+func cookieRequest(w *httptest.ResponseRecorder) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, cookie := range w.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	return req
+}
+
+func TestContextSignedCookie(t *testing.T) {
+	c, w := createTestContext(httptest.NewRequest(http.MethodGet, "/", nil))
+	c.engine.CookieKeyring = NewKeyring([]byte("secret"))
+	require.NoError(t, c.SetSignedCookie(&http.Cookie{Name: "user", Value: "gopher|admin", MaxAge: 60}))
+
+	cookie := w.Result().Cookies()[0]
+	assert.Equal(t, "/", cookie.Path)
+	assert.Equal(t, 60, cookie.MaxAge)
+	assert.NotContains(t, cookie.Value, "gopher")
+
+	c2, _ := createTestContext(cookieRequest(w))
+	c2.engine = c.engine
+	value, err := c2.SignedCookie("user")
+	require.NoError(t, err)
+	assert.Equal(t, "gopher|admin", value)
+
+	// a tampered value or a value moved to another cookie is rejected.
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: "user", Value: "Z29waGVy" + cookie.Value[strings.IndexByte(cookie.Value, '|'):]})
+	req.AddCookie(&http.Cookie{Name: "other", Value: cookie.Value})
+	c3, _ := createTestContext(req)
+	c3.engine = c.engine
+	_, err = c3.SignedCookie("user")
+	assert.ErrorIs(t, err, ErrInvalidCookie)
+	_, err = c3.SignedCookie("other")
+	assert.ErrorIs(t, err, ErrInvalidCookie)
+	_, err = c3.SignedCookie("missing")
+	assert.ErrorIs(t, err, http.ErrNoCookie)
+}
+
+func TestContextEncryptedCookie(t *testing.T) {
+	c, w := createTestContext(httptest.NewRequest(http.MethodGet, "/", nil))
+	c.engine.CookieKeyring = NewKeyring([]byte("secret"))
+	require.NoError(t, c.SetEncryptedCookie(&http.Cookie{Name: "token", Value: "s3cr3t", SameSite: http.SameSiteStrictMode}))
+	cookie := w.Result().Cookies()[0]
+	assert.NotContains(t, cookie.Value, "s3cr3t")
+	assert.Equal(t, http.SameSiteStrictMode, cookie.SameSite)
+
+	c2, _ := createTestContext(cookieRequest(w))
+	c2.engine = c.engine
+	value, err := c2.EncryptedCookie("token")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", value)
+
+	// a signed cookie is not an encrypted one.
+	_, err = c2.SignedCookie("token")
+	assert.ErrorIs(t, err, ErrInvalidCookie)
+}
+
+func TestContextCookieKeyRotation(t *testing.T) {
+	c, w := createTestContext(httptest.NewRequest(http.MethodGet, "/", nil))
+	keyring := NewKeyring([]byte("old"))
+	c.engine.CookieKeyring = keyring
+	require.NoError(t, c.SetSignedCookie(&http.Cookie{Name: "s", Value: "signed"}))
+	require.NoError(t, c.SetEncryptedCookie(&http.Cookie{Name: "e", Value: "encrypted"}))
+
+	keyring.Rotate([]byte("new"))
+	c2, _ := createTestContext(cookieRequest(w))
+	c2.engine = c.engine
+	value, err := c2.SignedCookie("s")
+	require.NoError(t, err)
+	assert.Equal(t, "signed", value)
+	value, err = c2.EncryptedCookie("e")
+	require.NoError(t, err)
+	assert.Equal(t, "encrypted", value)
+
+	keyring.Retire(1)
+	_, err = c2.SignedCookie("s")
+	assert.ErrorIs(t, err, ErrInvalidCookie)
+	_, err = c2.EncryptedCookie("e")
+	assert.ErrorIs(t, err, ErrInvalidCookie)
+}
+
+func TestKeyringExpiry(t *testing.T) {
+	keyring := NewKeyring([]byte("secret"))
+	expires := time.Now().Add(time.Minute).Unix()
+
+	signed := keyring.sign("s", "value", expires)
+	_, err := keyring.verify("s", signed, time.Now())
+	assert.NoError(t, err)
+	_, err = keyring.verify("s", signed, time.Now().Add(2*time.Minute))
+	assert.ErrorIs(t, err, ErrCookieExpired)
+
+	encrypted, err := keyring.encrypt("e", "value", expires)
+	require.NoError(t, err)
+	_, err = keyring.decrypt("e", encrypted, time.Now().Add(2*time.Minute))
+	assert.ErrorIs(t, err, ErrCookieExpired)
+}
+
+func TestContextCookieNoKeyring(t *testing.T) {
+	c, _ := createTestContext(httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.ErrorIs(t, c.SetSignedCookie(&http.Cookie{Name: "s"}), ErrNoKeyring)
+	_, err := c.EncryptedCookie("e")
+	assert.ErrorIs(t, err, ErrNoKeyring)
+	assert.Panics(t, func() { NewKeyring() })
+}
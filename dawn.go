@@ -90,6 +90,9 @@ type Engine struct {
 
 	ContextWithFallback bool
 
+	// CookieKeyring holds the keys of signed and encrypted cookies.
+	CookieKeyring *Keyring
+
 	delims           render.Delims
 	secureJSONPrefix string
 	HTMLRender       render.HTMLRender
@@ -104,6 +107,9 @@ type Engine struct {
 	maxSections      uint16
 	trustedProxies   []string
 	trustedCIDRs     []*net.IPNet
+	shutdownMu       sync.Mutex
+	onShutdown       []func()
+	shutdownOnce     sync.Once
 }
 
 var _ IRouter = (*Engine)(nil)
@@ -158,8 +164,10 @@ func (e *Engine) Delims(left, right string) *Engine {
 	return nil
 }
 
+// SecureJSONPrefix sets the secureJSONPrefix used in Context.SecureJSON.
 func (e *Engine) SecureJSONPrefix(prefix string) *Engine {
-	return nil
+	e.secureJSONPrefix = prefix
+	return e
 }
 
 func (e *Engine) LoadHTMLGlob(pattern string) {}
@@ -190,6 +198,28 @@ func (e *Engine) Run(addr ...string) error {
 	return nil
 }
 
+// RegisterOnShutdown registers a function to call on Shutdown, e.g. to close long-lived
+// streams which would otherwise keep http.Server.Shutdown waiting.
+func (e *Engine) RegisterOnShutdown(f func()) {
+	e.shutdownMu.Lock()
+	e.onShutdown = append(e.onShutdown, f)
+	e.shutdownMu.Unlock()
+}
+
+// Shutdown calls the functions registered with RegisterOnShutdown once, in order. It is meant
+// to be passed to http.Server.RegisterOnShutdown.
+func (e *Engine) Shutdown() {
+	e.shutdownOnce.Do(func() {
+		e.shutdownMu.Lock()
+		fns := e.onShutdown
+		e.shutdownMu.Unlock()
+
+		for _, f := range fns {
+			f()
+		}
+	})
+}
+
 func (e *Engine) prepareTrustedCIDRs() ([]*net.IPNet, error) {
 	return nil, nil
 }
@@ -0,0 +1,160 @@
+package dawn
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// DefaultDecompressMaxSize is the default maximum size of a decompressed request body.
+const DefaultDecompressMaxSize = 32 << 20 // 32MB
+
+// DecompressDecoder returns a reader of the decoded content of r.
+type DecompressDecoder func(r io.Reader) (io.ReadCloser, error)
+
+// DecompressConfig defines the config for the Decompress middleware.
+type DecompressConfig struct {
+	// MaxSize is the maximum size in bytes of a decompressed body, defaults to
+	// DefaultDecompressMaxSize. Reading beyond it fails with a *http.MaxBytesError.
+	MaxSize int64
+
+	// Decoders adds or replaces the decoders of content codings, by lower case name. gzip,
+	// x-gzip, deflate and br are supported by default, a nil decoder removes one of them.
+	Decoders map[string]DecompressDecoder
+}
+
+// Decompress returns a middleware which decodes the gzip, deflate and br request bodies,
+// see DecompressWithConfig.
+func Decompress() HandlerFunc {
+	return DecompressWithConfig(DecompressConfig{})
+}
+
+// DecompressWithConfig returns a middleware which decodes the request body according to its
+// Content-Encoding header, so the bindings, GetRawData and ShouldBindBodyWith see the decoded
+// bytes. The Content-Encoding and Content-Length headers are removed from the request.
+//
+// A body encoded with an unsupported content coding is rejected with 415 Unsupported Media
+// Type and the supported codings in the Accept-Encoding header, a malformed one with 400.
+// The decoded body is limited to config.MaxSize to protect against decompression bombs, the
+// Bind methods answer a larger body with 413 Request Entity Too Large.
+func DecompressWithConfig(config DecompressConfig) HandlerFunc {
+	if config.MaxSize <= 0 {
+		config.MaxSize = DefaultDecompressMaxSize
+	}
+	decoders := map[string]DecompressDecoder{
+		"gzip":    decodeGzip,
+		"x-gzip":  decodeGzip,
+		"deflate": decodeDeflate,
+		"br":      decodeBrotli,
+	}
+	for name, decoder := range config.Decoders {
+		if decoder == nil {
+			delete(decoders, strings.ToLower(name))
+			continue
+		}
+		decoders[strings.ToLower(name)] = decoder
+	}
+	supported := make([]string, 0, len(decoders))
+	for name := range decoders {
+		supported = append(supported, name)
+	}
+	sort.Strings(supported)
+	acceptEncoding := strings.Join(supported, ", ")
+
+	return func(c *Context) {
+		codings := contentCodings(c.Request.Header)
+		if len(codings) == 0 || c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+		for _, coding := range codings {
+			if decoders[coding] == nil {
+				c.Header("Accept-Encoding", acceptEncoding)
+				c.AbortWithError(http.StatusUnsupportedMediaType,
+					fmt.Errorf("unsupported content encoding %q", coding)).SetType(ErrorTypeBind)
+				return
+			}
+		}
+
+		body := &decodedBody{Reader: c.Request.Body, closers: []io.Closer{c.Request.Body}}
+		// the codings are listed in the order they were applied.
+		for i := len(codings) - 1; i >= 0; i-- {
+			rc, err := decoders[codings[i]](body.Reader)
+			if err != nil {
+				_ = body.Close()
+				c.AbortWithError(http.StatusBadRequest,
+					fmt.Errorf("invalid %s content encoding: %w", codings[i], err)).SetType(ErrorTypeBind)
+				return
+			}
+			body.Reader = rc
+			body.closers = append(body.closers, rc)
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, body, config.MaxSize)
+		c.Request.ContentLength = -1
+		c.Request.Header.Del("Content-Encoding")
+		c.Request.Header.Del("Content-Length")
+		c.Next()
+	}
+}
+
+// contentCodings returns the lower case content codings of the Content-Encoding headers,
+// without identity.
+func contentCodings(header http.Header) []string {
+	var codings []string
+	for _, value := range header.Values("Content-Encoding") {
+		for _, coding := range strings.Split(value, ",") {
+			coding = strings.ToLower(strings.TrimSpace(coding))
+			if coding != "" && coding != "identity" {
+				codings = append(codings, coding)
+			}
+		}
+	}
+	return codings
+}
+
+// decodedBody reads the decoded request body, closing it closes the decoders and the body.
+type decodedBody struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (b *decodedBody) Close() error {
+	var err error
+	for i := len(b.closers) - 1; i >= 0; i-- {
+		if cerr := b.closers[i].Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+func decodeGzip(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+// decodeDeflate decodes the zlib format of the deflate coding, and the raw deflate format
+// some clients send instead.
+func decodeDeflate(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(2)
+	if err != nil {
+		return nil, err
+	}
+	if header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
+
+func decodeBrotli(r io.Reader) (io.ReadCloser, error) {
+	return io.NopCloser(brotli.NewReader(r)), nil
+}
@@ -0,0 +1,139 @@
+package dawn
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func compressBody(t *testing.T, coding, body string) []byte {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch coding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "zlib":
+		w = zlib.NewWriter(&buf)
+	case "flate":
+		fw, err := flate.NewWriter(&buf, flate.DefaultCompression)
+		require.NoError(t, err)
+		w = fw
+	case "br":
+		w = brotli.NewWriter(&buf)
+	default:
+		t.Fatalf("unknown coding %q", coding)
+	}
+	_, err := w.Write([]byte(body))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+// serveDecompress binds the JSON body of a request with the given Content-Encoding.
+func serveDecompress(config DecompressConfig, encoding string, body []byte) (*Context, *httptest.ResponseRecorder, string) {
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	req.Header.Set("Content-Type", MIMEJSON)
+	req.Header.Set("Content-Encoding", encoding)
+	c, w := createTestContext(req)
+	var obj struct {
+		Name string `json:"name"`
+	}
+	serveTestContext(c, DecompressWithConfig(config), func(c *Context) {
+		if c.BindJSON(&obj) == nil {
+			c.Data(http.StatusOK, MIMEPlain, []byte(obj.Name))
+		}
+	})
+	c.Writer.WriteHeaderNow()
+	return c, w, obj.Name
+}
+
+func TestDecompress(t *testing.T) {
+	name := strings.Repeat("gopher", 100)
+	body := `{"name":"` + name + `"}`
+	tests := []struct {
+		encoding string
+		format   string
+	}{
+		{"gzip", "gzip"},
+		{"x-gzip", "gzip"},
+		{"GZIP", "gzip"},
+		{"deflate", "zlib"},
+		{"deflate", "flate"},
+		{"br", "br"},
+		{"identity, gzip", "gzip"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.encoding+"/"+tt.format, func(t *testing.T) {
+			c, w, got := serveDecompress(DecompressConfig{}, tt.encoding, compressBody(t, tt.format, body))
+			assert.Empty(t, c.Errors)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, name, got)
+			assert.Empty(t, c.Request.Header.Get("Content-Encoding"))
+			assert.Equal(t, int64(-1), c.Request.ContentLength)
+		})
+	}
+
+	// the codings are decoded in the reverse order they were applied.
+	nested := compressBody(t, "br", string(compressBody(t, "gzip", body)))
+	_, w, got := serveDecompress(DecompressConfig{}, "gzip, br", nested)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, name, got)
+}
+
+func TestDecompressUnsupported(t *testing.T) {
+	c, w, _ := serveDecompress(DecompressConfig{}, "zstd", []byte(`{}`))
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	assert.Equal(t, "br, deflate, gzip, x-gzip", w.Header().Get("Accept-Encoding"))
+	assert.True(t, c.IsAborted())
+	assert.Len(t, c.Errors.ByType(ErrorTypeBind), 1)
+
+	// a nil decoder removes a default one.
+	_, w, _ = serveDecompress(DecompressConfig{Decoders: map[string]DecompressDecoder{"BR": nil}},
+		"br", compressBody(t, "br", `{}`))
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	assert.Equal(t, "deflate, gzip, x-gzip", w.Header().Get("Accept-Encoding"))
+}
+
+func TestDecompressMalformed(t *testing.T) {
+	c, w, _ := serveDecompress(DecompressConfig{}, "gzip", []byte("not gzip"))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.True(t, c.IsAborted())
+	require.Len(t, c.Errors, 1)
+	assert.Contains(t, c.Errors[0].Error(), "invalid gzip content encoding")
+
+	// a truncated body fails when it is bound.
+	data := compressBody(t, "gzip", `{"name":"gopher"}`)
+	c, w, _ = serveDecompress(DecompressConfig{}, "gzip", data[:len(data)-4])
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Len(t, c.Errors.ByType(ErrorTypeBind), 1)
+}
+
+func TestDecompressMaxSize(t *testing.T) {
+	body := `{"name":"` + strings.Repeat("a", 1000) + `"}`
+	c, w, got := serveDecompress(DecompressConfig{MaxSize: 100}, "gzip", compressBody(t, "gzip", body))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Empty(t, got)
+	require.Len(t, c.Errors, 1)
+	var maxBytesErr *http.MaxBytesError
+	assert.ErrorAs(t, c.Errors[0], &maxBytesErr)
+
+	_, w, _ = serveDecompress(DecompressConfig{MaxSize: int64(len(body))}, "gzip", compressBody(t, "gzip", body))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestDecompressIdentity(t *testing.T) {
+	c, w, got := serveDecompress(DecompressConfig{}, "identity", []byte(`{"name":"gopher"}`))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "gopher", got)
+	assert.Equal(t, "identity", c.Request.Header.Get("Content-Encoding"))
+}
@@ -0,0 +1,202 @@
+package dawn
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestError(t *testing.T) {
+	baseError := errors.New("test error")
+	err := &Error{
+		Err:  baseError,
+		Type: ErrorTypePrivate,
+	}
+	assert.Equal(t, err.Error(), baseError.Error())
+	assert.Equal(t, H{"error": baseError.Error()}, err.JSON())
+
+	assert.Equal(t, err.SetType(ErrorTypePublic), err)
+	assert.Equal(t, ErrorTypePublic, err.Type)
+	assert.True(t, err.IsType(ErrorTypePublic))
+	assert.False(t, err.IsType(ErrorTypePrivate))
+
+	assert.Equal(t, err.SetMeta("some data"), err)
+	assert.Equal(t, "some data", err.Meta)
+	assert.Equal(t, H{
+		"error": baseError.Error(),
+		"meta":  "some data",
+	}, err.JSON())
+
+	jsonBytes, _ := json.Marshal(err)
+	assert.JSONEq(t, `{"error":"test error","meta":"some data"}`, string(jsonBytes))
+
+	err.SetMeta(H{ //nolint: errcheck
+		"status": "200",
+		"data":   "some data",
+	})
+	assert.Equal(t, H{
+		"error":  baseError.Error(),
+		"status": "200",
+		"data":   "some data",
+	}, err.JSON())
+
+	err.SetMeta(H{ //nolint: errcheck
+		"error":  "custom error",
+		"status": "200",
+		"data":   "some data",
+	})
+	assert.Equal(t, H{
+		"error":  "custom error",
+		"status": "200",
+		"data":   "some data",
+	}, err.JSON())
+
+	type customError struct {
+		status string
+		data   string
+	}
+	err.SetMeta(customError{status: "200", data: "other data"}) //nolint: errcheck
+	assert.Equal(t, customError{status: "200", data: "other data"}, err.JSON())
+}
+
+func TestErrorSlice(t *testing.T) {
+	errs := errorMsgs{
+		{Err: errors.New("first"), Type: ErrorTypePrivate},
+		{Err: errors.New("second"), Type: ErrorTypePrivate, Meta: "some data"},
+		{Err: errors.New("third"), Type: ErrorTypePublic, Meta: H{"status": "400"}},
+	}
+
+	assert.Equal(t, errs, errs.ByType(ErrorTypeAny))
+	assert.Equal(t, "third", errs.Last().Error())
+	assert.Equal(t, []string{"first", "second", "third"}, errs.Errors())
+	assert.Equal(t, []string{"third"}, errs.ByType(ErrorTypePublic).Errors())
+	assert.Equal(t, []string{"first", "second"}, errs.ByType(ErrorTypePrivate).Errors())
+	assert.Equal(t, []string{"first", "second", "third"}, errs.ByType(ErrorTypePublic|ErrorTypePrivate).Errors())
+	assert.Empty(t, errs.ByType(ErrorTypeBind))
+	assert.Empty(t, errs.ByType(ErrorTypeBind).String())
+
+	assert.Equal(t, `Error #01: first
+Error #02: second
+     Meta: some data
+Error #03: third
+     Meta: map[status:400]
+`, errs.String())
+	assert.Equal(t, []any{
+		H{"error": "first"},
+		H{"error": "second", "meta": "some data"},
+		H{"error": "third", "status": "400"},
+	}, errs.JSON())
+	jsonBytes, _ := json.Marshal(errs)
+	assert.JSONEq(t, `[{"error":"first"},{"error":"second","meta":"some data"},{"error":"third","status":"400"}]`, string(jsonBytes))
+
+	errs = errorMsgs{
+		{Err: errors.New("first"), Type: ErrorTypePrivate},
+	}
+	assert.Equal(t, H{"error": "first"}, errs.JSON())
+	jsonBytes, _ = json.Marshal(errs)
+	assert.JSONEq(t, `{"error":"first"}`, string(jsonBytes))
+
+	errs = errorMsgs{}
+	assert.Nil(t, errs.Last())
+	assert.Nil(t, errs.JSON())
+	assert.Empty(t, errs.String())
+}
+
+type testErr struct {
+	str string
+}
+
+func (e *testErr) Error() string {
+	return e.str
+}
+
+func TestErrorWithWrap(t *testing.T) {
+	innerErr := &testErr{str: "somme error"}
+	err := &Error{
+		Err:  fmt.Errorf("wrapped: %w", innerErr),
+		Type: ErrorTypeAny,
+	}
+
+	assert.True(t, errors.Is(err, innerErr))
+	var testErr *testErr
+	require.ErrorAs(t, err, &testErr)
+	assert.Equal(t, innerErr, testErr)
+
+	errs := errorMsgs{{Err: errors.New("first")}, err}
+	assert.True(t, errs.Is(innerErr))
+	assert.False(t, errs.Is(errors.New("other")))
+	testErr = nil
+	require.True(t, errs.As(&testErr))
+	assert.Equal(t, innerErr, testErr)
+	assert.False(t, errorMsgs{{Err: errors.New("first")}}.As(&testErr))
+}
+
+func TestContextError(t *testing.T) {
+	c, _ := createTestContext(httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Empty(t, c.Errors)
+
+	firstErr := errors.New("first error")
+	c.Error(firstErr) //nolint: errcheck
+	assert.Len(t, c.Errors, 1)
+	assert.Equal(t, "Error #01: first error\n", c.Errors.String())
+
+	secondErr := errors.New("second error")
+	c.Error(&Error{ //nolint: errcheck
+		Err:  secondErr,
+		Meta: "some data 2",
+		Type: ErrorTypePublic,
+	})
+	assert.Len(t, c.Errors, 2)
+
+	assert.Equal(t, firstErr, c.Errors[0].Err)
+	assert.Nil(t, c.Errors[0].Meta)
+	assert.Equal(t, ErrorTypePrivate, c.Errors[0].Type)
+
+	assert.Equal(t, secondErr, c.Errors[1].Err)
+	assert.Equal(t, "some data 2", c.Errors[1].Meta)
+	assert.Equal(t, ErrorTypePublic, c.Errors[1].Type)
+
+	assert.Equal(t, c.Errors.Last(), c.Errors[1])
+
+	// a wrapped *Error is attached as is.
+	wrapped := &Error{Err: errors.New("bind"), Type: ErrorTypeBind}
+	assert.Same(t, wrapped, c.Error(fmt.Errorf("wrap: %w", wrapped)))
+
+	assert.Panics(t, func() {
+		c.Error(nil) //nolint: errcheck
+	})
+}
+
+func TestContextAbortWithError(t *testing.T) {
+	c, w := createTestContext(httptest.NewRequest(http.MethodGet, "/", nil))
+	c.AbortWithError(http.StatusUnauthorized, errors.New("bad input")).SetMeta("some input") //nolint: errcheck
+	c.Writer.WriteHeaderNow()
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.True(t, c.IsAborted())
+	require.Len(t, c.Errors, 1)
+	assert.Equal(t, "some input", c.Errors[0].Meta)
+}
+
+func TestContextBindError(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":`))
+	req.Header.Set("Content-Type", MIMEJSON)
+	c, w := createTestContext(req)
+
+	var obj struct {
+		Name string `json:"name"`
+	}
+	assert.Error(t, c.Bind(&obj))
+	c.Writer.WriteHeaderNow()
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.True(t, c.IsAborted())
+	assert.Len(t, c.Errors.ByType(ErrorTypeBind), 1)
+	assert.Empty(t, c.Errors.ByType(ErrorTypeRender))
+}
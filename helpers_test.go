@@ -0,0 +1,24 @@
+package dawn
+
+import (
+	"net/http"
+	"net/http/httptest"
+)
+
+// createTestContext returns a Context of a new engine serving req, and the recorder of its
+// response.
+func createTestContext(req *http.Request) (*Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c := &Context{Request: req, engine: New(), index: -1}
+	c.writermem.reset(w)
+	c.Writer = &c.writermem
+	return c, w
+}
+
+// serveTestContext runs handlers on c and writes the response header if they did not.
+func serveTestContext(c *Context, handlers ...HandlerFunc) {
+	c.handlers = handlers
+	c.index = -1
+	c.Next()
+	c.Writer.WriteHeaderNow()
+}
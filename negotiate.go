@@ -0,0 +1,238 @@
+package dawn
+
+import (
+	"dawn/binding"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Specificity of a media range matching an offered type, the most specific
+// range that matches an offer determines its quality (RFC 9110, Section 12.5.1).
+const (
+	matchNone = iota - 1
+	matchAny
+	matchType
+	matchSuffix
+	matchExact
+	matchExactParams
+)
+
+// mediaRange is a single element of an Accept header, e.g. "application/*;q=0.8".
+type mediaRange struct {
+	typ     string
+	subtype string
+	params  map[string]string
+	q       float64
+}
+
+// parseAccept splits an Accept header into media ranges, malformed elements are skipped.
+func parseAccept(acceptHeader string) []mediaRange {
+	parts := splitQuoted(acceptHeader, ',')
+	out := make([]mediaRange, 0, len(parts))
+	for _, part := range parts {
+		if r, ok := parseMediaRange(part); ok {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// parseMediaRange parses a media type or media range with its parameters. The "q" parameter
+// is taken as the weight and any parameters after it (accept-ext) are ignored.
+func parseMediaRange(s string) (mediaRange, bool) {
+	parts := splitQuoted(s, ';')
+	if len(parts) == 0 {
+		return mediaRange{}, false
+	}
+
+	r := mediaRange{q: 1}
+	full := strings.ToLower(strings.TrimSpace(parts[0]))
+	if full == "*" { // some old clients send a bare "*"
+		full = "*/*"
+	}
+	typ, subtype, ok := strings.Cut(full, "/")
+	if !ok || typ == "" || subtype == "" || (typ == "*" && subtype != "*") {
+		return mediaRange{}, false
+	}
+	r.typ, r.subtype = typ, subtype
+
+	for _, param := range parts[1:] {
+		k, v, _ := strings.Cut(param, "=")
+		k = strings.ToLower(strings.TrimSpace(k))
+		v = strings.Trim(strings.TrimSpace(v), `"`)
+		if k == "" {
+			continue
+		}
+		if k == "q" {
+			q, err := strconv.ParseFloat(v, 64)
+			if err != nil || q < 0 || q > 1 {
+				return mediaRange{}, false
+			}
+			r.q = q
+			break
+		}
+		if r.params == nil {
+			r.params = make(map[string]string)
+		}
+		r.params[k] = v
+	}
+	return r, true
+}
+
+// match reports how specifically r matches the offered media type, or matchNone.
+func (r mediaRange) match(offer mediaRange) int {
+	if r.typ == "*" {
+		return matchAny
+	}
+	if r.typ != offer.typ {
+		return matchNone
+	}
+	if r.subtype == "*" {
+		return matchType
+	}
+	if r.subtype == offer.subtype {
+		for k, v := range r.params {
+			if !strings.EqualFold(offer.params[k], v) {
+				return matchNone
+			}
+		}
+		if len(r.params) > 0 {
+			return matchExactParams
+		}
+		return matchExact
+	}
+	// structured syntax suffixes (RFC 6839): "application/problem+json" is also JSON.
+	if suffix(r.subtype) == offer.subtype || suffix(offer.subtype) == r.subtype {
+		return matchSuffix
+	}
+	return matchNone
+}
+
+// renderFormat returns the media type of the render of the offered type, the one of its
+// structured syntax suffix for a type such as "application/problem+json".
+func renderFormat(offer string) string {
+	offer = mediaType(offer)
+	_, subtype, ok := strings.Cut(offer, "/")
+	if !ok {
+		return offer
+	}
+	switch suffix(subtype) {
+	case "json":
+		return binding.MIMEJSON
+	case "xml":
+		return binding.MIMEXML
+	case "yaml":
+		return binding.MIMEYAML
+	case "cbor":
+		return binding.MIMECBOR
+	}
+	return offer
+}
+
+func suffix(subtype string) string {
+	if i := strings.LastIndexByte(subtype, '+'); i >= 0 {
+		return subtype[i+1:]
+	}
+	return ""
+}
+
+// negotiate returns the offer with the highest quality. Ties are broken by the specificity of
+// the matching range, then by its position in accepted and finally by the order of offered.
+// It returns an empty string if no offer is acceptable.
+func negotiate(accepted []mediaRange, offered []string) string {
+	if len(accepted) == 0 {
+		return offered[0]
+	}
+
+	best, bestQ, bestLevel, bestIndex := "", 0.0, matchNone, 0
+	for _, offer := range offered {
+		o, ok := parseMediaRange(offer)
+		if !ok {
+			continue
+		}
+
+		level, q, index := matchNone, 0.0, 0
+		for i, r := range accepted {
+			if l := r.match(o); l > level {
+				level, q, index = l, r.q, i
+			}
+		}
+		if level == matchNone || q == 0 {
+			continue
+		}
+
+		if q > bestQ ||
+			(q == bestQ && level > bestLevel) ||
+			(q == bestQ && level == bestLevel && index < bestIndex) {
+			best, bestQ, bestLevel, bestIndex = offer, q, level, index
+		}
+	}
+	return best
+}
+
+// splitQuoted slices s into all substrings separated by sep, ignoring separators inside
+// quoted strings.
+func splitQuoted(s string, sep byte) []string {
+	var parts []string
+	quoted, start := false, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			quoted = !quoted
+		case sep:
+			if !quoted {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	parts = append(parts, s[start:])
+
+	out := parts[:0]
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// parseAcceptLanguage returns the language ranges of an Accept-Language header by decreasing
+// weight, e.g. [fr-CH fr en] for "en;q=0.5, fr-CH, fr;q=0.9". The wildcard and the ranges of
+// weight zero are skipped.
+func parseAcceptLanguage(header string) []string {
+	type languageRange struct {
+		tag string
+		q   float64
+	}
+	var ranges []languageRange
+	for _, part := range splitQuoted(header, ',') {
+		params := strings.Split(part, ";")
+		r := languageRange{tag: strings.TrimSpace(params[0]), q: 1}
+		for _, param := range params[1:] {
+			k, v, _ := strings.Cut(param, "=")
+			if strings.EqualFold(strings.TrimSpace(k), "q") {
+				q, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+				if err != nil {
+					r.q = 0
+				} else {
+					r.q = q
+				}
+			}
+		}
+		if r.tag != "" && r.tag != "*" && r.q > 0 {
+			ranges = append(ranges, r)
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].q > ranges[j].q
+	})
+	languages := make([]string, len(ranges))
+	for i, r := range ranges {
+		languages[i] = r.tag
+	}
+	return languages
+}
@@ -0,0 +1,124 @@
+package dawn
+
+import (
+	"dawn/render"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextNegotiateFormat(t *testing.T) {
+	tests := []struct {
+		accept  string
+		offered []string
+		want    string
+	}{
+		{"", []string{MIMEJSON, MIMEXML}, MIMEJSON},
+		{"*/*", []string{MIMEXML, MIMEJSON}, MIMEXML},
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", []string{MIMEJSON, MIMEXML, MIMEHTML}, MIMEHTML},
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", []string{MIMEJSON, MIMEXML}, MIMEXML},
+		{"application/vnd.acme+json", []string{MIMEXML, MIMEJSON}, MIMEJSON},
+		{"application/json", []string{"application/problem+json"}, "application/problem+json"},
+		{"application/*;q=0.5, application/json;q=0", []string{MIMEJSON, MIMEYAML}, MIMEYAML},
+		{"text/plain", []string{MIMEJSON}, ""},
+		{"*/*;q=1, application/json", []string{MIMEXML, MIMEJSON}, MIMEJSON},
+		{"application/xml;q=0.4, application/json;q=0.6", []string{MIMEXML, MIMEJSON}, MIMEJSON},
+		{"text/html;level=1", []string{MIMEHTML}, ""},
+		{"garbage", []string{MIMEJSON}, MIMEJSON},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept", tt.accept)
+		c, _ := createTestContext(req)
+		assert.Equal(t, tt.want, c.NegotiateFormat(tt.offered...), tt.accept)
+	}
+}
+
+func TestContextNegotiateFormatSetAccepted(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", MIMEJSON)
+	c, _ := createTestContext(req)
+	c.SetAccepted(MIMEXML)
+	assert.Equal(t, MIMEXML, c.NegotiateFormat(MIMEJSON, MIMEXML))
+}
+
+func TestContextNegotiate(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", "application/xml;q=0.5, application/json")
+	c, w := createTestContext(req)
+	c.Negotiate(http.StatusOK, Negotiate{Offered: []string{MIMEXML, MIMEJSON}, Data: H{"foo": "bar"}})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Accept", w.Header().Get("Vary"))
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, `{"foo":"bar"}`, w.Body.String())
+}
+
+type negotiateVersion struct {
+	Version int
+}
+
+func TestContextNegotiateVendorType(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", "application/vnd.acme.v2+json")
+	c, w := createTestContext(req)
+	c.Negotiate(http.StatusOK, Negotiate{
+		Offered:  []string{"application/vnd.acme.v1+xml", "application/vnd.acme.v2+json"},
+		JSONData: H{"version": 2},
+		Data:     H{"version": 1},
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/vnd.acme.v2+json", w.Header().Get("Content-Type"))
+	assert.Equal(t, `{"version":2}`, w.Body.String())
+
+	req.Header.Set("Accept", "application/vnd.acme.v1+xml")
+	c, w = createTestContext(req)
+	c.Negotiate(http.StatusOK, Negotiate{
+		Offered: []string{"application/vnd.acme.v1+xml", "application/vnd.acme.v2+json"},
+		Data:    negotiateVersion{Version: 1},
+	})
+	assert.Equal(t, "application/vnd.acme.v1+xml", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "<Version>1</Version>")
+}
+
+func TestContextNegotiateNotAcceptable(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", "text/plain")
+	c, w := createTestContext(req)
+	c.Negotiate(http.StatusOK, Negotiate{Offered: []string{MIMEJSON, MIMEYAML}, Data: 1})
+
+	assert.Equal(t, http.StatusNotAcceptable, w.Code)
+	assert.True(t, c.IsAborted())
+	assert.Equal(t, "406 not acceptable, available: application/json, application/x-yaml", w.Body.String())
+}
+
+type testHTMLRender struct{}
+
+func (testHTMLRender) Instance(name string, data any) render.Render {
+	return render.Data{ContentType: "text/html; charset=utf-8", Data: []byte("<p>" + name + "</p>")}
+}
+
+func TestContextNegotiateHTML(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", MIMEHTML)
+	c, w := createTestContext(req)
+	serveTestContext(c, func(c *Context) {
+		c.Negotiate(http.StatusOK, Negotiate{Offered: []string{MIMEHTML}, HTMLName: "index.tmpl", Data: 1})
+	})
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.ErrorIs(t, c.Errors.Last(), ErrNoHTMLRender)
+
+	c, w = createTestContext(req)
+	c.engine.HTMLRender = testHTMLRender{}
+	c.Negotiate(http.StatusOK, Negotiate{Offered: []string{MIMEHTML}, HTMLName: "index.tmpl", Data: 1})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "<p>index.tmpl</p>", w.Body.String())
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	assert.Equal(t, []string{"fr-CH", "fr", "en"}, parseAcceptLanguage("en;q=0.5, fr-CH, fr;q=0.9"))
+	assert.Equal(t, []string{"de"}, parseAcceptLanguage("*, de, it;q=0, es;q=x"))
+	assert.Empty(t, parseAcceptLanguage(""))
+}
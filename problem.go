@@ -0,0 +1,373 @@
+package dawn
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"runtime/debug"
+	"sort"
+	"strconv"
+
+	"dawn/binding"
+	"dawn/render"
+)
+
+// Content-Type MIME of the problem details documents (RFC 9457).
+const (
+	MIMEProblemJSON = "application/problem+json"
+	MIMEProblemXML  = "application/problem+xml"
+)
+
+// problemXMLNamespace is the namespace of problem+xml documents (RFC 9457, Appendix B).
+const problemXMLNamespace = "urn:ietf:rfc:7807"
+
+// Problem is a problem details document as defined by RFC 9457. A *Problem is an error too,
+// a handler can pass it to c.Error to have it rendered as is by Problems.
+type Problem struct {
+	// Type is a URI reference identifying the problem type, defaults to "about:blank".
+	Type string
+	// Title is a short summary of the problem type, defaults to the status text.
+	Title string
+	// Status is the HTTP status code.
+	Status int
+	// Detail is an explanation specific to this occurrence of the problem.
+	Detail string
+	// Instance is a URI reference identifying this occurrence, defaults to the request URI.
+	Instance string
+	// Errors lists the invalid fields of a request failing validation.
+	Errors []ProblemError
+	// Extensions are additional members of the document.
+	Extensions map[string]any
+}
+
+// ProblemError describes an invalid field of a request. Source is set for the fields bound by
+// binding.All, Tag for the validation errors.
+type ProblemError struct {
+	Source  string `json:"source,omitempty" xml:"source,omitempty"`
+	Field   string `json:"field" xml:"field"`
+	Tag     string `json:"tag,omitempty" xml:"tag,omitempty"`
+	Message string `json:"message" xml:"message"`
+}
+
+var (
+	_ error          = (*Problem)(nil)
+	_ json.Marshaler = (*Problem)(nil)
+	_ xml.Marshaler  = (*Problem)(nil)
+)
+
+// Error implements the error interface.
+func (p *Problem) Error() string {
+	if p.Detail != "" {
+		return p.Title + ": " + p.Detail
+	}
+	return p.Title
+}
+
+type problemJSON struct {
+	Type     string         `json:"type,omitempty"`
+	Title    string         `json:"title,omitempty"`
+	Status   int            `json:"status,omitempty"`
+	Detail   string         `json:"detail,omitempty"`
+	Instance string         `json:"instance,omitempty"`
+	Errors   []ProblemError `json:"errors,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface, the extensions are members of the
+// document next to the standard ones, which take precedence.
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	b, err := json.Marshal(problemJSON{
+		Type:     p.Type,
+		Title:    p.Title,
+		Status:   p.Status,
+		Detail:   p.Detail,
+		Instance: p.Instance,
+		Errors:   p.Errors,
+	})
+	if err != nil || len(p.Extensions) == 0 {
+		return b, err
+	}
+
+	var buf bytes.Buffer
+	buf.Write(b[:len(b)-1])
+	first := len(b) == 2
+	for _, k := range p.extensionKeys() {
+		v, err := json.Marshal(p.Extensions[k])
+		if err != nil {
+			return nil, err
+		}
+		key, _ := json.Marshal(k)
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// MarshalXML implements the xml.Marshaler interface following RFC 9457, Appendix B.
+func (p *Problem) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	start := xml.StartElement{Name: xml.Name{Space: problemXMLNamespace, Local: "problem"}}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	members := []struct {
+		name  string
+		value string
+	}{
+		{"type", p.Type},
+		{"title", p.Title},
+		{"status", strconv.Itoa(p.Status)},
+		{"detail", p.Detail},
+		{"instance", p.Instance},
+	}
+	for _, m := range members {
+		if m.value == "" || m.value == "0" {
+			continue
+		}
+		if err := e.EncodeElement(m.value, xml.StartElement{Name: xml.Name{Local: m.name}}); err != nil {
+			return err
+		}
+	}
+	if len(p.Errors) > 0 {
+		errs := struct {
+			Items []ProblemError `xml:"i"`
+		}{p.Errors}
+		if err := e.EncodeElement(errs, xml.StartElement{Name: xml.Name{Local: "errors"}}); err != nil {
+			return err
+		}
+	}
+	for _, k := range p.extensionKeys() {
+		if err := e.EncodeElement(p.Extensions[k], xml.StartElement{Name: xml.Name{Local: k}}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// extensionKeys returns the sorted keys of the extensions which are no standard member.
+func (p *Problem) extensionKeys() []string {
+	keys := make([]string, 0, len(p.Extensions))
+	for k := range p.Extensions {
+		switch k {
+		case "type", "title", "status", "detail", "instance", "errors":
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ProblemMapper maps an error to a problem, a nil problem passes the error on to the next rule.
+type ProblemMapper func(c *Context, err error) *Problem
+
+type problemRule struct {
+	match  func(err error) bool
+	mapper ProblemMapper
+}
+
+// ProblemConfig defines the config for Problems.
+type ProblemConfig struct {
+	// Default maps the errors no rule matched, it receives the *Error of c.Errors so the
+	// error type can be checked. By default bind errors are reported with
+	// status 400 and their message, public errors with their message and private errors
+	// without detail, with the status set by AbortWithError or 500.
+	Default ProblemMapper
+
+	// Logger logs the recovered panics, defaults to log.Printf.
+	Logger func(format string, args ...any)
+
+	rules []problemRule
+}
+
+// MapIs registers mapper for the errors matching target according to errors.Is, e.g. a
+// sentinel error. Rules are tried in the order they were registered.
+func (config *ProblemConfig) MapIs(target error, mapper ProblemMapper) {
+	config.rules = append(config.rules, problemRule{
+		match:  func(err error) bool { return errors.Is(err, target) },
+		mapper: mapper,
+	})
+}
+
+// MapAs registers mapper for the errors having the type of target according to errors.As,
+// e.g. MapAs((*os.PathError)(nil), mapper). Rules are tried in the order they were registered.
+func (config *ProblemConfig) MapAs(target error, mapper ProblemMapper) {
+	assert1(target != nil, "target must not be a nil interface")
+	typ := reflect.TypeOf(target)
+	config.rules = append(config.rules, problemRule{
+		match:  func(err error) bool { return errors.As(err, reflect.New(typ).Interface()) },
+		mapper: mapper,
+	})
+}
+
+// MapStatus registers a rule reporting the errors matching target according to errors.Is with
+// the given status and title, and the error message as detail if detail is true.
+func (config *ProblemConfig) MapStatus(target error, status int, title string, detail bool) {
+	config.MapIs(target, func(c *Context, err error) *Problem {
+		p := &Problem{Status: status, Title: title}
+		if detail {
+			p.Detail = err.Error()
+		}
+		return p
+	})
+}
+
+// Problems returns a middleware which reports the last error of c.Errors, and panics, as
+// problem details documents (RFC 9457) once the handlers returned, unless the response was
+// already written. The document is rendered as application/problem+xml for clients
+// preferring XML and as application/problem+json otherwise.
+func Problems(config ProblemConfig) HandlerFunc {
+	if config.Default == nil {
+		config.Default = defaultProblem
+	}
+	if config.Logger == nil {
+		config.Logger = log.Printf
+	}
+
+	return func(c *Context) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+			config.Logger("[Problems] panic recovered: %v\n%s", rec, debug.Stack())
+
+			err, ok := rec.(error)
+			if !ok {
+				err = fmt.Errorf("panic: %v", rec)
+			}
+			c.Status(http.StatusInternalServerError)
+			c.Abort()
+			config.writeProblem(c, c.Error(err))
+		}()
+
+		c.Next()
+
+		if last := c.Errors.Last(); last != nil {
+			config.writeProblem(c, last)
+		}
+	}
+}
+
+func (config *ProblemConfig) writeProblem(c *Context, err *Error) {
+	if c.Writer.Written() {
+		return
+	}
+
+	var p *Problem
+	if !errors.As(err, &p) {
+		for _, rule := range config.rules {
+			if rule.match(err.Err) {
+				if p = rule.mapper(c, err.Err); p != nil {
+					break
+				}
+			}
+		}
+	}
+	if p == nil {
+		p = config.Default(c, err)
+	}
+	if p == nil {
+		p = defaultProblem(c, err)
+	}
+	c.Problem(p)
+}
+
+// defaultProblem implements the default of ProblemConfig.Default.
+func defaultProblem(c *Context, err error) *Problem {
+	p := &Problem{Status: c.Writer.Status()}
+	if p.Status < http.StatusBadRequest {
+		p.Status = http.StatusInternalServerError
+	}
+
+	var msg *Error
+	if !errors.As(err, &msg) {
+		msg = &Error{Err: err, Type: ErrorTypePrivate}
+	}
+	switch {
+	case msg.IsType(ErrorTypeBind):
+		if p.Status == http.StatusInternalServerError {
+			p.Status = http.StatusBadRequest
+		}
+		if p.Errors = validationProblemErrors(c, msg.Err); p.Errors != nil {
+			p.Detail = "The request failed validation."
+		} else {
+			p.Detail = msg.Error()
+		}
+	case msg.IsType(ErrorTypePublic):
+		p.Detail = msg.Error()
+	}
+	return p
+}
+
+// validationProblemErrors returns the invalid fields reported by binding.Validator, with the
+// messages in the language of the request, or nil if err is no validation error.
+func validationProblemErrors(c *Context, err error) []ProblemError {
+	languages := c.acceptLanguages()
+
+	var sourceErrs binding.SourceErrors
+	if errors.As(err, &sourceErrs) {
+		var errs []ProblemError
+		for _, se := range sourceErrs {
+			var fe *binding.FieldError
+			if !errors.As(se.Err, &fe) {
+				errs = append(errs, ProblemError{Source: se.Source, Field: se.Field, Message: se.Err.Error()})
+				continue
+			}
+			fe = fe.Translate(languages...)
+			errs = append(errs, ProblemError{Source: se.Source, Field: fe.Field, Tag: fe.Tag, Message: fe.Message})
+		}
+		return errs
+	}
+
+	fieldErrs, ok := binding.AsValidationErrors(err)
+	if !ok {
+		return nil
+	}
+	fieldErrs = fieldErrs.Translate(languages...)
+	errs := make([]ProblemError, len(fieldErrs))
+	for i, fe := range fieldErrs {
+		errs[i] = ProblemError{Field: fe.Field, Tag: fe.Tag, Message: fe.Message}
+	}
+	return errs
+}
+
+// Problem writes p as a problem details document, negotiated between
+// application/problem+json and application/problem+xml, with p.Status as status code.
+// The missing type, title and instance members are filled with their defaults.
+func (c *Context) Problem(p *Problem) {
+	if p.Status == 0 {
+		p.Status = http.StatusInternalServerError
+	}
+	if p.Type == "" {
+		p.Type = "about:blank"
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+	if p.Instance == "" && c.Request != nil {
+		p.Instance = c.Request.URL.RequestURI()
+	}
+
+	c.Writer.Header().Add("Vary", "Accept")
+	switch c.NegotiateFormat(MIMEProblemJSON, MIMEProblemXML, binding.MIMEXML2) {
+	case MIMEProblemXML, binding.MIMEXML2:
+		c.Header("Content-Type", MIMEProblemXML+"; charset=utf-8")
+		c.Render(p.Status, render.XML{Data: p})
+	default:
+		c.Header("Content-Type", MIMEProblemJSON)
+		c.Render(p.Status, render.JSON{Data: p})
+	}
+}
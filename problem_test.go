@@ -0,0 +1,244 @@
+package dawn
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errThingNotFound = errors.New("thing not found")
+
+// serveProblem runs handler after the Problems middleware and returns the response.
+func serveProblem(config ProblemConfig, accept string, handler HandlerFunc) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/things/1?verbose=1", strings.NewReader(`{"name":""}`))
+	req.Header.Set("Content-Type", MIMEJSON)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	c, w := createTestContext(req)
+	serveTestContext(c, Problems(config), handler)
+	return w
+}
+
+func decodeProblem(t *testing.T, w *httptest.ResponseRecorder) map[string]any {
+	assert.Equal(t, MIMEProblemJSON, w.Header().Get("Content-Type"))
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc), w.Body.String())
+	return doc
+}
+
+func TestProblemMarshalJSON(t *testing.T) {
+	p := &Problem{
+		Type:   "https://example.com/probs/out-of-credit",
+		Title:  "You do not have enough credit.",
+		Status: http.StatusForbidden,
+		Detail: "Your current balance is 30, but that costs 50.",
+		Extensions: map[string]any{
+			"balance":  30,
+			"accounts": []string{"/account/12345"},
+			"title":    "ignored",
+		},
+	}
+	b, err := json.Marshal(p)
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":"https://example.com/probs/out-of-credit","title":"You do not have enough credit.",`+
+		`"status":403,"detail":"Your current balance is 30, but that costs 50.",`+
+		`"accounts":["/account/12345"],"balance":30}`, string(b))
+	assert.Equal(t, "You do not have enough credit.: Your current balance is 30, but that costs 50.", p.Error())
+
+	b, err = json.Marshal(&Problem{Extensions: map[string]any{"a": 1}})
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":1}`, string(b))
+
+	_, err = json.Marshal(&Problem{Extensions: map[string]any{"a": make(chan int)}})
+	assert.Error(t, err)
+}
+
+func TestProblemMarshalXML(t *testing.T) {
+	p := &Problem{
+		Type:       "about:blank",
+		Title:      "Bad Request",
+		Status:     http.StatusBadRequest,
+		Errors:     []ProblemError{{Field: "name", Tag: "required", Message: "name is required"}},
+		Extensions: map[string]any{"balance": 30},
+	}
+	b, err := xml.Marshal(p)
+	require.NoError(t, err)
+	assert.Equal(t, `<problem xmlns="urn:ietf:rfc:7807"><type>about:blank</type><title>Bad Request</title>`+
+		`<status>400</status><errors><i><field>name</field><tag>required</tag><message>name is required</message></i></errors>`+
+		`<balance>30</balance></problem>`, string(b))
+}
+
+func TestProblemsDefault(t *testing.T) {
+	// private errors are reported without detail.
+	w := serveProblem(ProblemConfig{}, "", func(c *Context) {
+		_ = c.Error(errors.New("database password is wrong"))
+	})
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, map[string]any{
+		"type":     "about:blank",
+		"title":    "Internal Server Error",
+		"status":   float64(500),
+		"instance": "/things/1?verbose=1",
+	}, decodeProblem(t, w))
+	assert.Equal(t, "Accept", w.Header().Get("Vary"))
+
+	// public errors keep the status of AbortWithError and their message.
+	w = serveProblem(ProblemConfig{}, "", func(c *Context) {
+		_ = c.AbortWithError(http.StatusConflict, errors.New("name already taken")).SetType(ErrorTypePublic)
+	})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	doc := decodeProblem(t, w)
+	assert.Equal(t, "Conflict", doc["title"])
+	assert.Equal(t, "name already taken", doc["detail"])
+}
+
+func TestProblemsValidation(t *testing.T) {
+	w := serveProblem(ProblemConfig{}, "", func(c *Context) {
+		var obj struct {
+			Name string `json:"name" binding:"required"`
+		}
+		_ = c.Bind(&obj)
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	doc := decodeProblem(t, w)
+	assert.Equal(t, "The request failed validation.", doc["detail"])
+	errs, ok := doc["errors"].([]any)
+	require.True(t, ok)
+	require.Len(t, errs, 1)
+	fieldErr := errs[0].(map[string]any)
+	assert.Equal(t, "name", fieldErr["field"])
+	assert.Equal(t, "required", fieldErr["tag"])
+	assert.NotEmpty(t, fieldErr["message"])
+
+	// other bind errors are reported with their message.
+	w = serveProblem(ProblemConfig{}, "", func(c *Context) {
+		var obj struct {
+			Name int `json:"name"`
+		}
+		_ = c.Bind(&obj)
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	doc = decodeProblem(t, w)
+	assert.NotEmpty(t, doc["detail"])
+	assert.Nil(t, doc["errors"])
+}
+
+func TestProblemsMapping(t *testing.T) {
+	var config ProblemConfig
+	config.MapIs(errThingNotFound, func(c *Context, err error) *Problem {
+		if c.Request.URL.Query().Get("verbose") == "" {
+			return nil
+		}
+		return &Problem{Status: http.StatusNotFound, Detail: err.Error(), Extensions: map[string]any{"id": 1}}
+	})
+	config.MapStatus(errThingNotFound, http.StatusGone, "Gone", false)
+	config.MapAs((*fs.PathError)(nil), func(c *Context, err error) *Problem {
+		var pathErr *fs.PathError
+		errors.As(err, &pathErr)
+		return &Problem{Status: http.StatusUnprocessableEntity, Type: "https://example.com/probs/file", Detail: pathErr.Op}
+	})
+
+	// the first matching rule returning a problem wins.
+	w := serveProblem(config, "", func(c *Context) {
+		_ = c.Error(fmt.Errorf("load: %w", errThingNotFound))
+	})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	doc := decodeProblem(t, w)
+	assert.Equal(t, "Not Found", doc["title"])
+	assert.Equal(t, "load: thing not found", doc["detail"])
+	assert.Equal(t, float64(1), doc["id"])
+
+	w = serveProblem(config, "", func(c *Context) {
+		_, err := os.Open("/does/not/exist")
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+	})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	doc = decodeProblem(t, w)
+	assert.Equal(t, "https://example.com/probs/file", doc["type"])
+	assert.Equal(t, "open", doc["detail"])
+
+	// a *Problem is rendered as is, only the last error is reported.
+	w = serveProblem(config, "", func(c *Context) {
+		_ = c.Error(errThingNotFound)
+		_ = c.Error(&Problem{Status: http.StatusPaymentRequired, Detail: "out of credit"})
+	})
+	assert.Equal(t, http.StatusPaymentRequired, w.Code)
+	assert.Equal(t, "out of credit", decodeProblem(t, w)["detail"])
+
+	// a written response is left as is.
+	w = serveProblem(config, "", func(c *Context) {
+		_ = c.Error(errThingNotFound)
+		_, _ = c.Writer.WriteString("ok")
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "ok", w.Body.String())
+}
+
+func TestProblemsMapStatus(t *testing.T) {
+	var config ProblemConfig
+	// a rule returning no problem passes the error on to the next one.
+	config.MapIs(errThingNotFound, func(*Context, error) *Problem { return nil })
+	config.MapStatus(errThingNotFound, http.StatusNotFound, "Thing Not Found", true)
+
+	req := httptest.NewRequest(http.MethodGet, "/things/1", nil)
+	c, w := createTestContext(req)
+	serveTestContext(c, Problems(config), func(c *Context) {
+		_ = c.Error(errThingNotFound)
+	})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	doc := decodeProblem(t, w)
+	assert.Equal(t, "Thing Not Found", doc["title"])
+	assert.Equal(t, "thing not found", doc["detail"])
+	assert.Equal(t, "/things/1", doc["instance"])
+}
+
+func TestProblemsPanic(t *testing.T) {
+	var logged string
+	config := ProblemConfig{Logger: func(format string, args ...any) {
+		logged = fmt.Sprintf(format, args...)
+	}}
+	w := serveProblem(config, "", func(c *Context) {
+		panic("boom")
+	})
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	doc := decodeProblem(t, w)
+	assert.Equal(t, "Internal Server Error", doc["title"])
+	assert.Nil(t, doc["detail"])
+	assert.Contains(t, logged, "panic recovered: boom")
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		serveProblem(config, "", func(c *Context) {
+			panic(http.ErrAbortHandler)
+		})
+	})
+}
+
+func TestProblemsXML(t *testing.T) {
+	w := serveProblem(ProblemConfig{}, "application/xml", func(c *Context) {
+		_ = c.AbortWithError(http.StatusForbidden, errors.New("forbidden")).SetType(ErrorTypePublic)
+	})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, MIMEProblemXML+"; charset=utf-8", w.Header().Get("Content-Type"))
+
+	var doc struct {
+		XMLName xml.Name `xml:"urn:ietf:rfc:7807 problem"`
+		Title   string   `xml:"title"`
+		Status  int      `xml:"status"`
+		Detail  string   `xml:"detail"`
+	}
+	require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &doc), w.Body.String())
+	assert.Equal(t, "Forbidden", doc.Title)
+	assert.Equal(t, http.StatusForbidden, doc.Status)
+	assert.Equal(t, "forbidden", doc.Detail)
+}
@@ -0,0 +1,61 @@
+package render
+
+import (
+	"net/http"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// CBOR contains the given interface object.
+type CBOR struct {
+	Data any
+	// Canonical enables the core deterministic encoding (RFC 8949, Section 4.2.1), e.g. to
+	// sign or hash the output.
+	Canonical bool
+}
+
+var cborContentType = []string{"application/cbor"}
+
+var (
+	// cborEncMode encodes time values as epoch-based date/time (tag 1).
+	cborEncMode = mustEncMode(cbor.EncOptions{
+		Time:    cbor.TimeUnixDynamic,
+		TimeTag: cbor.EncTagRequired,
+	})
+
+	cborCanonicalEncMode = mustEncMode(func() cbor.EncOptions {
+		opts := cbor.CoreDetEncOptions()
+		opts.Time = cbor.TimeUnixDynamic
+		opts.TimeTag = cbor.EncTagRequired
+		return opts
+	}())
+)
+
+func mustEncMode(opts cbor.EncOptions) cbor.EncMode {
+	em, err := opts.EncMode()
+	if err != nil {
+		panic(err)
+	}
+	return em
+}
+
+// Render (CBOR) encodes the given interface object and writes data with custom ContentType.
+func (r CBOR) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+
+	em := cborEncMode
+	if r.Canonical {
+		em = cborCanonicalEncMode
+	}
+	b, err := em.Marshal(r.Data)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// WriteContentType (CBOR) writes CBOR ContentType.
+func (r CBOR) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, cborContentType)
+}
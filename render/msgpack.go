@@ -0,0 +1,32 @@
+package render
+
+import (
+	"net/http"
+
+	"dawn/codec/msgpack"
+
+	"github.com/ugorji/go/codec"
+)
+
+// MsgPack contains the given interface object.
+type MsgPack struct {
+	Data any
+}
+
+var msgpackContentType = []string{"application/msgpack"}
+
+// WriteContentType (MsgPack) writes MsgPack ContentType.
+func (r MsgPack) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, msgpackContentType)
+}
+
+// Render (MsgPack) encodes the given interface object and writes data with custom ContentType.
+func (r MsgPack) Render(w http.ResponseWriter) error {
+	return WriteMsgPack(w, r.Data)
+}
+
+// WriteMsgPack writes MsgPack ContentType and encodes the given interface object.
+func WriteMsgPack(w http.ResponseWriter, obj any) error {
+	writeContentType(w, msgpackContentType)
+	return codec.NewEncoder(w, msgpack.Handle).Encode(obj)
+}
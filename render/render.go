@@ -9,3 +9,28 @@ type Render interface {
 	// WriteContentType writes custom ContentType.
 	WriteContentType(w http.ResponseWriter)
 }
+
+var (
+	_ Render = JSON{}
+	_ Render = IndentedJSON{}
+	_ Render = SecureJSON{}
+	_ Render = JsonpJSON{}
+	_ Render = AsciiJSON{}
+	_ Render = PureJSON{}
+	_ Render = XML{}
+	_ Render = YAML{}
+	_ Render = TOML{}
+	_ Render = ProtoBuf{}
+	_ Render = MsgPack{}
+	_ Render = CBOR{}
+	_ Render = Data{}
+	_ Render = SSEvent{}
+	_ Render = Reader{}
+)
+
+func writeContentType(w http.ResponseWriter, value []string) {
+	header := w.Header()
+	if val := header["Content-Type"]; len(val) == 0 {
+		header["Content-Type"] = value
+	}
+}
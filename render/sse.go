@@ -0,0 +1,114 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"dawn/codec/json"
+)
+
+// SSEvent contains the fields of a single Server-Sent Event.
+type SSEvent struct {
+	// ID sets the event source's last event ID, sent back by reconnecting clients.
+	ID string
+	// Event is the event type, clients dispatch it to the matching listener.
+	Event string
+	// Retry is the reconnection time in milliseconds, zero leaves it unchanged.
+	Retry uint
+	// Comment is written as comment lines which are ignored by clients, e.g. keep-alives.
+	Comment string
+	// Data is written as is for strings, numbers and []byte and JSON-encoded otherwise.
+	Data any
+}
+
+var sseContentType = []string{"text/event-stream"}
+
+var (
+	sseFieldReplacer = strings.NewReplacer("\n", "", "\r", "", "\x00", "")
+	sseLineReplacer  = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+)
+
+// Render (SSEvent) writes the event in the text/event-stream format.
+func (r SSEvent) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	return WriteSSEvent(w, r)
+}
+
+// WriteContentType (SSEvent) writes the event stream ContentType and disables caching.
+func (r SSEvent) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, sseContentType)
+	header := w.Header()
+	if header.Get("Cache-Control") == "" {
+		header.Set("Cache-Control", "no-cache")
+	}
+}
+
+// WriteSSEvent encodes the event and writes it to w, it is terminated by a blank line.
+func WriteSSEvent(w io.Writer, r SSEvent) error {
+	var buf strings.Builder
+	if r.Comment != "" {
+		writeSSELines(&buf, ":", r.Comment)
+	}
+	if r.ID != "" {
+		buf.WriteString("id: ")
+		buf.WriteString(sseFieldReplacer.Replace(r.ID))
+		buf.WriteByte('\n')
+	}
+	if r.Event != "" {
+		buf.WriteString("event: ")
+		buf.WriteString(sseFieldReplacer.Replace(r.Event))
+		buf.WriteByte('\n')
+	}
+	if r.Retry > 0 {
+		buf.WriteString("retry: ")
+		buf.WriteString(strconv.FormatUint(uint64(r.Retry), 10))
+		buf.WriteByte('\n')
+	}
+	if r.Data != nil {
+		data, err := sseData(r.Data)
+		if err != nil {
+			return err
+		}
+		writeSSELines(&buf, "data:", data)
+	}
+	buf.WriteByte('\n')
+
+	_, err := io.WriteString(w, buf.String())
+	return err
+}
+
+// writeSSELines writes every line of value as its own field, so multi-line data survives.
+func writeSSELines(buf *strings.Builder, field, value string) {
+	for _, line := range strings.Split(sseLineReplacer.Replace(value), "\n") {
+		buf.WriteString(field)
+		buf.WriteByte(' ')
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+}
+
+func sseData(data any) (string, error) {
+	switch v := data.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	}
+
+	switch reflect.ValueOf(data).Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.String:
+		return fmt.Sprint(data), nil
+	}
+
+	jsonBytes, err := json.API().Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	return string(jsonBytes), nil
+}
@@ -0,0 +1,26 @@
+package render
+
+import (
+	"net/http"
+
+	"github.com/BurntSushi/toml"
+)
+
+// TOML contains the given interface object.
+type TOML struct {
+	Data any
+}
+
+var tomlContentType = []string{"application/toml; charset=utf-8"}
+
+// Render (TOML) marshals the given interface object and writes data with custom ContentType.
+func (r TOML) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+
+	return toml.NewEncoder(w).Encode(r.Data)
+}
+
+// WriteContentType (TOML) writes TOML ContentType for response.
+func (r TOML) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, tomlContentType)
+}
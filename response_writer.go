@@ -2,6 +2,7 @@ package dawn
 
 import (
 	"bufio"
+	"io"
 	"net"
 	"net/http"
 )
@@ -40,6 +41,15 @@ type responseWriter struct {
 	http.ResponseWriter
 	size   int
 	status int
+
+	// beforeWrite are run once before the header is written, see before.
+	beforeWrite []func()
+}
+
+// writeHooks is implemented by the writers holding the functions to run before the response
+// header is written, a writer wrapping another one runs them before committing the response.
+type writeHooks interface {
+	takeBeforeWrite() []func()
 }
 
 var _ ResponseWriter = (*responseWriter)(nil)
@@ -48,42 +58,93 @@ func (w *responseWriter) Unwrap() http.ResponseWriter {
 	return w.ResponseWriter
 }
 
-func (w *responseWriter) reset(writer http.ResponseWriter) {}
+func (w *responseWriter) reset(writer http.ResponseWriter) {
+	w.ResponseWriter = writer
+	w.size = noWritten
+	w.status = defaultStatus
+	w.beforeWrite = nil
+}
+
+// before registers f to run before the header is written, e.g. to set a cookie whose value
+// is known only once the handlers are done writing to it.
+func (w *responseWriter) before(f func()) {
+	w.beforeWrite = append(w.beforeWrite, f)
+}
+
+func (w *responseWriter) takeBeforeWrite() []func() {
+	hooks := w.beforeWrite
+	w.beforeWrite = nil
+	return hooks
+}
 
-func (w *responseWriter) WriteHeader(code int) {}
+func (w *responseWriter) WriteHeader(code int) {
+	if code > 0 && w.status != code {
+		if w.Written() {
+			return
+		}
+		w.status = code
+	}
+}
 
-func (w *responseWriter) WriteHeaderNow() {}
+func (w *responseWriter) WriteHeaderNow() {
+	if !w.Written() {
+		for _, f := range w.takeBeforeWrite() {
+			f()
+		}
+		w.size = 0
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+}
 
 func (w *responseWriter) Write(data []byte) (n int, err error) {
-	return 0, nil
+	w.WriteHeaderNow()
+	n, err = w.ResponseWriter.Write(data)
+	w.size += n
+	return
 }
 
 func (w *responseWriter) WriteString(s string) (n int, err error) {
-	return 0, nil
+	w.WriteHeaderNow()
+	n, err = io.WriteString(w.ResponseWriter, s)
+	w.size += n
+	return
 }
 
 func (w *responseWriter) Status() int {
-	return 0
+	return w.status
 }
 
 func (w *responseWriter) Size() int {
-	return 0
+	return w.size
 }
 
 func (w *responseWriter) Written() bool {
-	return false
+	return w.size != noWritten
 }
 
+// Hijack implements the http.Hijacker interface.
 func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
-	return nil, nil, nil
+	if w.size < 0 {
+		w.size = 0
+	}
+	w.beforeWrite = nil
+	return w.ResponseWriter.(http.Hijacker).Hijack()
 }
 
+// CloseNotify implements the http.CloseNotifier interface.
 func (w *responseWriter) CloseNotify() <-chan bool {
-	return nil
+	return w.ResponseWriter.(http.CloseNotifier).CloseNotify()
 }
 
-func (w *responseWriter) Flush() {}
+// Flush implements the http.Flusher interface.
+func (w *responseWriter) Flush() {
+	w.WriteHeaderNow()
+	w.ResponseWriter.(http.Flusher).Flush()
+}
 
 func (w *responseWriter) Pusher() (pusher http.Pusher) {
+	if pusher, ok := w.ResponseWriter.(http.Pusher); ok {
+		return pusher
+	}
 	return nil
 }
@@ -0,0 +1,335 @@
+package dawn
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// SessionKey is the key that the current *Session is stored under in Context.Keys.
+const SessionKey = "_dawn/session"
+
+const (
+	defaultSessionCookieName = "dawn_session"
+	defaultSessionMaxAge     = 24 * time.Hour
+	flashesKey               = "_flashes"
+)
+
+// ErrSessionWritten is returned when a session cookie has to be set after the response
+// headers were written.
+var ErrSessionWritten = errors.New("dawn: response already written, session cookie can not be set")
+
+// Store persists encoded sessions under their ID.
+type Store interface {
+	// Load returns the session stored under id, or nil if there is none or it expired.
+	Load(c *Context, id string) ([]byte, error)
+	// Save stores the session under id, it expires after maxAge.
+	Save(c *Context, id string, data []byte, maxAge time.Duration) error
+	// Delete removes the session stored under id.
+	Delete(c *Context, id string) error
+	// Regenerate moves the session stored under id to a new ID and returns it.
+	Regenerate(c *Context, id string) (string, error)
+}
+
+// SessionConfig defines the config for Sessions middleware.
+type SessionConfig struct {
+	// Store persists the sessions.
+	Store Store
+	// MaxAge is the time sessions are kept in Store after their last save, defaults to 24 hours.
+	MaxAge time.Duration
+	// Cookie is the template of the session ID cookie, its name defaults to "dawn_session".
+	Cookie http.Cookie
+}
+
+// Sessions returns a middleware that makes a Session available through c.Session() and saves
+// it if it was modified, right before the response header is written, so stores keeping the
+// data in a cookie can still set it, and again after the handlers for the later changes.
+func Sessions(config SessionConfig) HandlerFunc {
+	assert1(config.Store != nil, "session store must not be nil")
+	if config.MaxAge <= 0 {
+		config.MaxAge = defaultSessionMaxAge
+	}
+	if config.Cookie.Name == "" {
+		config.Cookie.Name = defaultSessionCookieName
+	}
+
+	return func(c *Context) {
+		s := &Session{c: c, sessionState: &sessionState{config: &config}}
+		c.Set(SessionKey, s)
+		failed := false
+		save := func() {
+			if err := s.Save(); err != nil && !failed {
+				failed = true
+				_ = c.Error(err)
+			}
+		}
+		c.writermem.before(save)
+		c.Next()
+		save()
+	}
+}
+
+// Session returns the session of the current request, it panics if the Sessions middleware
+// is not installed. The values of the session are shared with copies made by c.Copy.
+func (c *Context) Session() *Session {
+	if s, ok := c.Get(SessionKey); ok {
+		return s.(*Session)
+	}
+	panic("dawn: Sessions middleware is not installed")
+}
+
+// Session holds the values of a client session, values are JSON encoded. It is loaded lazily
+// and is safe for concurrent use.
+type Session struct {
+	// c is the context the session reads the request of and sets the cookie with, each copy
+	// of the context has a Session of its own sharing the state.
+	c *Context
+	*sessionState
+}
+
+type sessionState struct {
+	mu     sync.Mutex
+	config *SessionConfig
+	id     string
+	values map[string]json.RawMessage
+	loaded bool
+	dirty  bool
+}
+
+// bind returns the session with the state of s for the context c.
+func (s *Session) bind(c *Context) *Session {
+	return &Session{c: c, sessionState: s.sessionState}
+}
+
+// ID returns the session ID, empty until a value is set.
+func (s *Session) ID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_ = s.load()
+	return s.id
+}
+
+// Get decodes the value stored under key into ptr and reports whether it exists.
+func (s *Session) Get(key string, ptr any) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return false, err
+	}
+	raw, ok := s.values[key]
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(raw, ptr)
+}
+
+// GetString returns the value stored under key as a string, empty if missing or not a string.
+func (s *Session) GetString(key string) (str string) {
+	_, _ = s.Get(key, &str)
+	return
+}
+
+// GetInt returns the value stored under key as an int, zero if missing or not a number.
+func (s *Session) GetInt(key string) (i int) {
+	_, _ = s.Get(key, &i)
+	return
+}
+
+// GetBool returns the value stored under key as a bool, false if missing or not a bool.
+func (s *Session) GetBool(key string) (b bool) {
+	_, _ = s.Get(key, &b)
+	return
+}
+
+// Set stores value under key, it must be JSON encodable.
+func (s *Session) Set(key string, value any) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return err
+	}
+	if err := s.ensureID(); err != nil {
+		return err
+	}
+	s.values[key] = raw
+	s.dirty = true
+	return nil
+}
+
+// Delete removes the value stored under key.
+func (s *Session) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return err
+	}
+	if _, ok := s.values[key]; ok {
+		delete(s.values, key)
+		s.dirty = true
+	}
+	return nil
+}
+
+// Clear removes all the values but keeps the session.
+func (s *Session) Clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return err
+	}
+	if len(s.values) > 0 {
+		s.values = make(map[string]json.RawMessage)
+		s.dirty = true
+	}
+	return nil
+}
+
+// AddFlash adds a message which is returned once by Flashes, e.g. on the next request.
+func (s *Session) AddFlash(message string) error {
+	var flashes []string
+	if _, err := s.Get(flashesKey, &flashes); err != nil {
+		return err
+	}
+	return s.Set(flashesKey, append(flashes, message))
+}
+
+// Flashes returns the flash messages and removes them from the session.
+func (s *Session) Flashes() []string {
+	var flashes []string
+	if ok, _ := s.Get(flashesKey, &flashes); ok {
+		_ = s.Delete(flashesKey)
+	}
+	return flashes
+}
+
+// RotateID moves the session to a new ID, keeping its values. Call it when the privilege
+// level changes, e.g. on login, to prevent session fixation.
+func (s *Session) RotateID() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return err
+	}
+	if s.id == "" {
+		return s.ensureID()
+	}
+
+	id, err := s.config.Store.Regenerate(s.c, s.id)
+	if err != nil {
+		return err
+	}
+	s.id = id
+	s.dirty = true
+	return s.setCookie(s.config.Cookie.MaxAge)
+}
+
+// Destroy deletes the session from the store and expires its cookie, e.g. on logout.
+func (s *Session) Destroy() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return err
+	}
+	s.values = make(map[string]json.RawMessage)
+	s.dirty = false
+	if s.id == "" {
+		return nil
+	}
+
+	if err := s.config.Store.Delete(s.c, s.id); err != nil {
+		return err
+	}
+	s.id = ""
+	return s.setCookie(-1)
+}
+
+// Save stores the session if it was modified. It is called by the Sessions middleware before
+// the response is written and after the handlers, so the changes made in goroutines through a
+// copied context must be saved with the Session of the copy. A CookieStore can not save them,
+// it fails with ErrSessionWritten as the response can not be written through a copy.
+func (s *Session) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.dirty {
+		return nil
+	}
+	data, err := json.Marshal(s.values)
+	if err != nil {
+		return err
+	}
+	if err := s.config.Store.Save(s.c, s.id, data, s.config.MaxAge); err != nil {
+		return err
+	}
+	s.dirty = false
+	return nil
+}
+
+func (s *Session) load() error {
+	if s.loaded {
+		return nil
+	}
+
+	s.values = make(map[string]json.RawMessage)
+	if id, err := s.c.Cookie(s.config.Cookie.Name); err == nil && id != "" {
+		data, err := s.config.Store.Load(s.c, id)
+		if err != nil {
+			return err
+		}
+		if data != nil {
+			if err := json.Unmarshal(data, &s.values); err != nil {
+				return err
+			}
+			s.id = id
+		}
+	}
+	s.loaded = true
+	return nil
+}
+
+// ensureID assigns a new ID to a session which has none yet and sets its cookie.
+func (s *Session) ensureID() error {
+	if s.id != "" {
+		return nil
+	}
+	id, err := newSessionID()
+	if err != nil {
+		return err
+	}
+	s.id = id
+	return s.setCookie(s.config.Cookie.MaxAge)
+}
+
+func (s *Session) setCookie(maxAge int) error {
+	if s.c.Writer.Written() {
+		return ErrSessionWritten
+	}
+	cookie := s.config.Cookie
+	cookie.Value = s.id
+	cookie.MaxAge = maxAge
+	s.c.SetCookieData(&cookie)
+	return nil
+}
+
+// newSessionID returns a random, URL and cookie safe, session ID.
+func newSessionID() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return cookieEncoding.EncodeToString(b), nil
+}
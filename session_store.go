@@ -0,0 +1,319 @@
+package dawn
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	_ Store = (*MemoryStore)(nil)
+	_ Store = (*FileStore)(nil)
+	_ Store = (*CookieStore)(nil)
+)
+
+// maxCookieSize is the size limit of a cookie most browsers accept.
+const maxCookieSize = 4096
+
+// ErrCookieTooLarge is returned by CookieStore when the session does not fit into a cookie.
+var ErrCookieTooLarge = errors.New("dawn: session too large for a cookie")
+
+// MemoryStore keeps sessions in memory, expired sessions are evicted periodically.
+// Sessions are lost on restart and not shared between processes.
+type MemoryStore struct {
+	mu       sync.Mutex
+	sessions map[string]memorySession
+	done     chan struct{}
+	once     sync.Once
+}
+
+type memorySession struct {
+	data    []byte
+	expires time.Time
+}
+
+// NewMemoryStore returns a MemoryStore evicting expired sessions every cleanupInterval, a
+// non-positive interval only evicts them when they are loaded.
+func NewMemoryStore(cleanupInterval time.Duration) *MemoryStore {
+	s := &MemoryStore{
+		sessions: make(map[string]memorySession),
+		done:     make(chan struct{}),
+	}
+	if cleanupInterval > 0 {
+		go s.cleanup(cleanupInterval)
+	}
+	return s
+}
+
+// Load implements the Store interface.
+func (s *MemoryStore) Load(_ *Context, id string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok {
+		return nil, nil
+	}
+	if time.Now().After(session.expires) {
+		delete(s.sessions, id)
+		return nil, nil
+	}
+	return session.data, nil
+}
+
+// Save implements the Store interface.
+func (s *MemoryStore) Save(_ *Context, id string, data []byte, maxAge time.Duration) error {
+	s.mu.Lock()
+	s.sessions[id] = memorySession{data: data, expires: time.Now().Add(maxAge)}
+	s.mu.Unlock()
+	return nil
+}
+
+// Delete implements the Store interface.
+func (s *MemoryStore) Delete(_ *Context, id string) error {
+	s.mu.Lock()
+	delete(s.sessions, id)
+	s.mu.Unlock()
+	return nil
+}
+
+// Regenerate implements the Store interface.
+func (s *MemoryStore) Regenerate(_ *Context, id string) (string, error) {
+	newID, err := newSessionID()
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if session, ok := s.sessions[id]; ok {
+		s.sessions[newID] = session
+		delete(s.sessions, id)
+	}
+	return newID, nil
+}
+
+// Len returns the number of stored sessions, including expired ones not evicted yet.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.sessions)
+}
+
+// Close stops the periodic eviction.
+func (s *MemoryStore) Close() {
+	s.once.Do(func() {
+		close(s.done)
+	})
+}
+
+func (s *MemoryStore) cleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case now := <-ticker.C:
+			s.mu.Lock()
+			for id, session := range s.sessions {
+				if now.After(session.expires) {
+					delete(s.sessions, id)
+				}
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// FileStore keeps every session in its own file of a directory. File names are derived from
+// a hash of the session ID, so IDs never reach the file system.
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore returns a FileStore writing to dir, which is created if needed.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Load implements the Store interface.
+func (s *FileStore) Load(_ *Context, id string) ([]byte, error) {
+	content, err := os.ReadFile(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data, ok := decodeSessionFile(content, time.Now())
+	if !ok {
+		_ = os.Remove(s.path(id))
+		return nil, nil
+	}
+	return data, nil
+}
+
+// Save implements the Store interface, the file is replaced atomically.
+func (s *FileStore) Save(_ *Context, id string, data []byte, maxAge time.Duration) error {
+	content := make([]byte, 8, 8+len(data))
+	binary.BigEndian.PutUint64(content, uint64(time.Now().Add(maxAge).Unix()))
+	content = append(content, data...)
+
+	f, err := os.CreateTemp(s.dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(content); err == nil {
+		err = f.Close()
+	} else {
+		f.Close()
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), s.path(id))
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+	}
+	return err
+}
+
+// Delete implements the Store interface.
+func (s *FileStore) Delete(_ *Context, id string) error {
+	if err := os.Remove(s.path(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Regenerate implements the Store interface.
+func (s *FileStore) Regenerate(_ *Context, id string) (string, error) {
+	newID, err := newSessionID()
+	if err != nil {
+		return "", err
+	}
+	if err := os.Rename(s.path(id), s.path(newID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	return newID, nil
+}
+
+// Cleanup removes the files of expired sessions, call it periodically.
+func (s *FileStore) Cleanup() error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		name := filepath.Join(s.dir, entry.Name())
+		content, err := os.ReadFile(name)
+		if err != nil {
+			continue
+		}
+		if _, ok := decodeSessionFile(content, now); !ok {
+			_ = os.Remove(name)
+		}
+	}
+	return nil
+}
+
+func (s *FileStore) path(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:]))
+}
+
+// decodeSessionFile returns the session data of a file, ok is false if it expired.
+func decodeSessionFile(content []byte, now time.Time) (data []byte, ok bool) {
+	if len(content) < 8 {
+		return nil, false
+	}
+	if expires := int64(binary.BigEndian.Uint64(content)); now.Unix() >= expires {
+		return nil, false
+	}
+	return content[8:], true
+}
+
+// CookieStore keeps the session data in a cookie signed with Engine.CookieKeyring, so no
+// server side state is needed. The data is readable by the client and limited to about 4KB.
+type CookieStore struct {
+	cookie http.Cookie
+}
+
+// NewCookieStore returns a CookieStore writing the session data to a cookie using the given
+// template, its name defaults to "dawn_session_data".
+func NewCookieStore(cookie http.Cookie) *CookieStore {
+	if cookie.Name == "" {
+		cookie.Name = defaultSessionCookieName + "_data"
+	}
+	return &CookieStore{cookie: cookie}
+}
+
+// Load implements the Store interface. A missing, tampered or expired cookie is no session.
+func (s *CookieStore) Load(c *Context, id string) ([]byte, error) {
+	value, err := c.SignedCookie(s.cookie.Name)
+	if errors.Is(err, ErrNoKeyring) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, nil
+	}
+
+	// the ID is signed along with the data, so data can not be paired with another session.
+	cookieID, data, ok := strings.Cut(value, "|")
+	if !ok || cookieID != id {
+		return nil, nil
+	}
+	return []byte(data), nil
+}
+
+// Save implements the Store interface, it fails if the response was already written.
+func (s *CookieStore) Save(c *Context, id string, data []byte, maxAge time.Duration) error {
+	if c.Writer.Written() {
+		return ErrSessionWritten
+	}
+	cookie := s.cookie
+	cookie.Value = id + "|" + string(data)
+	cookie.MaxAge = int(maxAge / time.Second)
+	signed, err := c.signCookie(&cookie)
+	if err != nil {
+		return err
+	}
+
+	c.cookieDefaults(signed)
+	if len(signed.String()) > maxCookieSize {
+		return ErrCookieTooLarge
+	}
+	c.SetCookieData(signed)
+	return nil
+}
+
+// Delete implements the Store interface.
+func (s *CookieStore) Delete(c *Context, _ string) error {
+	cookie := s.cookie
+	cookie.MaxAge = -1
+	c.SetCookieData(&cookie)
+	return nil
+}
+
+// Regenerate implements the Store interface. The data follows the session when it is saved
+// under the new ID.
+func (s *CookieStore) Regenerate(_ *Context, _ string) (string, error) {
+	return newSessionID()
+}
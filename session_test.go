@@ -0,0 +1,255 @@
+package dawn
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveSession runs handler after the Sessions middleware on a request carrying cookies, and
+// returns the context and its response.
+func serveSession(e *Engine, config SessionConfig, cookies []*http.Cookie, handler HandlerFunc) (*Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	c, w := createTestContext(req)
+	c.engine = e
+	serveTestContext(c, Sessions(config), handler)
+	return c, w
+}
+
+// mergeCookies returns the cookies of prev updated by the ones set in w, expired ones removed.
+func mergeCookies(prev []*http.Cookie, w *httptest.ResponseRecorder) []*http.Cookie {
+	merged := make(map[string]*http.Cookie)
+	for _, cookie := range prev {
+		merged[cookie.Name] = cookie
+	}
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.MaxAge < 0 {
+			delete(merged, cookie.Name)
+			continue
+		}
+		merged[cookie.Name] = cookie
+	}
+	cookies := make([]*http.Cookie, 0, len(merged))
+	for _, cookie := range merged {
+		cookies = append(cookies, cookie)
+	}
+	return cookies
+}
+
+func sessionStores(t *testing.T) map[string]Store {
+	fileStore, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	memoryStore := NewMemoryStore(0)
+	t.Cleanup(memoryStore.Close)
+	return map[string]Store{
+		"memory": memoryStore,
+		"file":   fileStore,
+		"cookie": NewCookieStore(http.Cookie{}),
+	}
+}
+
+func TestSessions(t *testing.T) {
+	for name, store := range sessionStores(t) {
+		t.Run(name, func(t *testing.T) {
+			e := New()
+			e.CookieKeyring = NewKeyring([]byte("secret"))
+			config := SessionConfig{Store: store}
+
+			c, w := serveSession(e, config, nil, func(c *Context) {
+				require.NoError(t, c.Session().Set("user", "gopher"))
+				require.NoError(t, c.Session().AddFlash("welcome"))
+				_, _ = c.Writer.WriteString("hello")
+			})
+			assert.Empty(t, c.Errors)
+			assert.Equal(t, "hello", w.Body.String())
+			cookies := mergeCookies(nil, w)
+			require.NotEmpty(t, cookies)
+
+			c, w = serveSession(e, config, cookies, func(c *Context) {
+				assert.Equal(t, "gopher", c.Session().GetString("user"))
+				assert.Equal(t, []string{"welcome"}, c.Session().Flashes())
+				assert.Equal(t, "gopher", c.Copy().Session().GetString("user"))
+				c.Status(http.StatusNoContent)
+			})
+			assert.Empty(t, c.Errors)
+			cookies = mergeCookies(cookies, w)
+
+			c, _ = serveSession(e, config, cookies, func(c *Context) {
+				assert.Equal(t, "gopher", c.Session().GetString("user"))
+				assert.Nil(t, c.Session().Flashes())
+			})
+			assert.Empty(t, c.Errors)
+		})
+	}
+}
+
+func TestSessionRotateID(t *testing.T) {
+	for name, store := range sessionStores(t) {
+		t.Run(name, func(t *testing.T) {
+			e := New()
+			e.CookieKeyring = NewKeyring([]byte("secret"))
+			config := SessionConfig{Store: store}
+
+			_, w := serveSession(e, config, nil, func(c *Context) {
+				require.NoError(t, c.Session().Set("user", "gopher"))
+			})
+			cookies := mergeCookies(nil, w)
+
+			var oldID, newID string
+			c, w := serveSession(e, config, cookies, func(c *Context) {
+				oldID = c.Session().ID()
+				require.NoError(t, c.Session().RotateID())
+				newID = c.Session().ID()
+				_, _ = c.Writer.WriteString("logged in")
+			})
+			assert.Empty(t, c.Errors)
+			assert.NotEmpty(t, oldID)
+			assert.NotEqual(t, oldID, newID)
+			cookies = mergeCookies(cookies, w)
+
+			c, _ = serveSession(e, config, cookies, func(c *Context) {
+				assert.Equal(t, newID, c.Session().ID())
+				assert.Equal(t, "gopher", c.Session().GetString("user"))
+			})
+			assert.Empty(t, c.Errors)
+
+			// the old ID no longer gives access to the session.
+			fixed := []*http.Cookie{{Name: defaultSessionCookieName, Value: oldID}}
+			for _, cookie := range cookies {
+				if cookie.Name != defaultSessionCookieName {
+					fixed = append(fixed, cookie)
+				}
+			}
+			serveSession(e, config, fixed, func(c *Context) {
+				assert.Empty(t, c.Session().GetString("user"))
+			})
+		})
+	}
+}
+
+func TestSessionDestroy(t *testing.T) {
+	store := NewMemoryStore(0)
+	defer store.Close()
+	config := SessionConfig{Store: store}
+
+	_, w := serveSession(New(), config, nil, func(c *Context) {
+		require.NoError(t, c.Session().Set("user", "gopher"))
+	})
+	cookies := mergeCookies(nil, w)
+	assert.Equal(t, 1, store.Len())
+
+	c, w := serveSession(New(), config, cookies, func(c *Context) {
+		require.NoError(t, c.Session().Destroy())
+		assert.Empty(t, c.Session().ID())
+	})
+	assert.Empty(t, c.Errors)
+	assert.Equal(t, 0, store.Len())
+	assert.Empty(t, mergeCookies(cookies, w))
+}
+
+func TestSessionWritten(t *testing.T) {
+	store := NewMemoryStore(0)
+	defer store.Close()
+
+	c, _ := serveSession(New(), SessionConfig{Store: store}, nil, func(c *Context) {
+		_, _ = c.Writer.WriteString("hello")
+		assert.ErrorIs(t, c.Session().Set("user", "gopher"), ErrSessionWritten)
+	})
+	assert.Equal(t, 0, store.Len())
+	assert.Empty(t, c.Errors)
+}
+
+func TestSessionCopy(t *testing.T) {
+	for name, store := range sessionStores(t) {
+		t.Run(name, func(t *testing.T) {
+			e := New()
+			e.CookieKeyring = NewKeyring([]byte("secret"))
+			config := SessionConfig{Store: store}
+
+			_, w := serveSession(e, config, nil, func(c *Context) {
+				require.NoError(t, c.Session().Set("user", "gopher"))
+			})
+			cookies := mergeCookies(nil, w)
+
+			release := make(chan struct{})
+			saved := make(chan error, 1)
+			c, _ := serveSession(e, config, cookies, func(c *Context) {
+				cp := c.Copy()
+				go func() {
+					<-release
+					s := cp.Session()
+					if err := s.Set("job", "done"); err != nil {
+						saved <- err
+						return
+					}
+					saved <- s.Save()
+				}()
+			})
+			assert.Empty(t, c.Errors)
+			// the context is reused for another request while the goroutine runs.
+			close(release)
+			c.Request = httptest.NewRequest(http.MethodGet, "/other", nil)
+			c.writermem.reset(httptest.NewRecorder())
+			err := <-saved
+
+			c, _ = serveSession(e, config, cookies, func(c *Context) {
+				assert.Equal(t, "gopher", c.Session().GetString("user"))
+				if name == "cookie" {
+					assert.Empty(t, c.Session().GetString("job"))
+				} else {
+					assert.Equal(t, "done", c.Session().GetString("job"))
+				}
+			})
+			assert.Empty(t, c.Errors)
+			if name == "cookie" {
+				assert.ErrorIs(t, err, ErrSessionWritten)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCookieStoreTooLarge(t *testing.T) {
+	e := New()
+	e.CookieKeyring = NewKeyring([]byte("secret"))
+	config := SessionConfig{Store: NewCookieStore(http.Cookie{})}
+
+	c, w := serveSession(e, config, nil, func(c *Context) {
+		require.NoError(t, c.Session().Set("data", strings.Repeat("x", maxCookieSize)))
+		c.Status(http.StatusOK)
+	})
+	require.Len(t, c.Errors, 1)
+	assert.ErrorIs(t, c.Errors[0], ErrCookieTooLarge)
+	for _, cookie := range w.Result().Cookies() {
+		assert.NotEqual(t, "dawn_session_data", cookie.Name)
+	}
+}
+
+func TestCookieStoreTimeout(t *testing.T) {
+	e := New()
+	e.CookieKeyring = NewKeyring([]byte("secret"))
+	config := SessionConfig{Store: NewCookieStore(http.Cookie{}), MaxAge: time.Minute}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	c, w := createTestContext(req)
+	c.engine = e
+	serveTestContext(c, Sessions(config), Timeout(time.Minute), func(c *Context) {
+		require.NoError(t, c.Session().Set("user", "gopher"))
+		_, _ = c.Writer.WriteString("hello")
+	})
+	assert.Empty(t, c.Errors)
+
+	c, _ = serveSession(e, config, mergeCookies(nil, w), func(c *Context) {
+		assert.Equal(t, "gopher", c.Session().GetString("user"))
+	})
+	assert.Empty(t, c.Errors)
+}
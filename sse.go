@@ -0,0 +1,88 @@
+package dawn
+
+import (
+	"dawn/render"
+	"strconv"
+	"sync"
+)
+
+// ReplayBuffer keeps the most recent Server-Sent Events so reconnecting clients can resume
+// from their Last-Event-ID. It is safe for concurrent use.
+type ReplayBuffer struct {
+	mu     sync.Mutex
+	events []render.SSEvent
+	start  int
+	size   int
+	seq    uint64
+}
+
+// NewReplayBuffer returns a ReplayBuffer holding at most size events.
+func NewReplayBuffer(size int) *ReplayBuffer {
+	assert1(size > 0, "replay buffer size must be positive")
+	return &ReplayBuffer{events: make([]render.SSEvent, size)}
+}
+
+// Add appends the event to the buffer, evicting the oldest one when it is full. Events
+// without an ID get a sequential one. It returns the stored event.
+func (b *ReplayBuffer) Add(event render.SSEvent) render.SSEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.seq++
+	if event.ID == "" {
+		event.ID = strconv.FormatUint(b.seq, 10)
+	}
+
+	if b.size < len(b.events) {
+		b.events[(b.start+b.size)%len(b.events)] = event
+		b.size++
+	} else {
+		b.events[b.start] = event
+		b.start = (b.start + 1) % len(b.events)
+	}
+	return event
+}
+
+// Since returns the buffered events sent after the event with the given ID. If lastID is no
+// longer buffered all buffered events are returned and ok is false, an empty lastID returns
+// nothing since the client has not seen any event yet.
+func (b *ReplayBuffer) Since(lastID string) (events []render.SSEvent, ok bool) {
+	if lastID == "" {
+		return nil, true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for i := b.size - 1; i >= 0; i-- {
+		if b.at(i).ID == lastID {
+			return b.slice(i + 1), true
+		}
+	}
+	return b.slice(0), false
+}
+
+// Len returns the number of buffered events.
+func (b *ReplayBuffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.size
+}
+
+func (b *ReplayBuffer) at(i int) render.SSEvent {
+	return b.events[(b.start+i)%len(b.events)]
+}
+
+func (b *ReplayBuffer) slice(from int) []render.SSEvent {
+	events := make([]render.SSEvent, 0, b.size-from)
+	for i := from; i < b.size; i++ {
+		events = append(events, b.at(i))
+	}
+	return events
+}
+
+func (b *ReplayBuffer) all() []render.SSEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.slice(0)
+}
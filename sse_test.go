@@ -0,0 +1,107 @@
+package dawn
+
+import (
+	"context"
+	"dawn/codec/json"
+	"dawn/render"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextSSEvent(t *testing.T) {
+	c, w := createTestContext(httptest.NewRequest(http.MethodGet, "/", nil))
+	c.Render(-1, render.SSEvent{ID: "4\n2", Event: "msg", Retry: 300, Data: "a\r\nb\nc"})
+	c.SSEvent("json", map[string]int{"x": 1})
+
+	assert.Equal(t, "id: 42\nevent: msg\nretry: 300\ndata: a\ndata: b\ndata: c\n\nevent: json\ndata: {\"x\":1}\n\n", w.Body.String())
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+}
+
+func TestContextSSEventStream(t *testing.T) {
+	c, w := createTestContext(httptest.NewRequest(http.MethodGet, "/", nil))
+	events := make(chan render.SSEvent, 2)
+	events <- render.SSEvent{ID: "1", Data: "a"}
+	events <- render.SSEvent{ID: "2", Data: "b"}
+	close(events)
+
+	assert.False(t, c.SSEventStream(events, 0))
+	assert.Equal(t, "id: 1\ndata: a\n\nid: 2\ndata: b\n\n", w.Body.String())
+	assert.True(t, w.Flushed)
+}
+
+func TestContextSSEventStreamClientGone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	c, w := createTestContext(req)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	assert.True(t, c.SSEventStream(make(chan render.SSEvent), 10*time.Millisecond))
+	assert.Contains(t, w.Body.String(), ": keep-alive\n\n")
+}
+
+func TestContextLastEventID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Last-Event-ID", "42")
+	c, _ := createTestContext(req)
+	assert.Equal(t, "42", c.LastEventID())
+}
+
+func TestReplayBuffer(t *testing.T) {
+	b := NewReplayBuffer(3)
+	for i := 0; i < 5; i++ {
+		b.Add(render.SSEvent{Data: i})
+	}
+	assert.Equal(t, 3, b.Len())
+
+	events, ok := b.Since("4")
+	assert.True(t, ok)
+	assert.Equal(t, []render.SSEvent{{ID: "5", Data: 4}}, events)
+
+	events, ok = b.Since("1")
+	assert.False(t, ok)
+	assert.Len(t, events, 3)
+	assert.Equal(t, "3", events[0].ID)
+
+	events, ok = b.Since("5")
+	assert.True(t, ok)
+	assert.Empty(t, events)
+
+	events, ok = b.Since("")
+	assert.True(t, ok)
+	assert.Empty(t, events)
+
+	assert.Equal(t, "custom", b.Add(render.SSEvent{ID: "custom"}).ID)
+	assert.Panics(t, func() { NewReplayBuffer(0) })
+}
+
+func TestSSEventRenderSanitizesFields(t *testing.T) {
+	w := httptest.NewRecorder()
+	err := render.SSEvent{Event: "a\nb", Comment: "x\ny"}.Render(w)
+	assert.NoError(t, err)
+	assert.Equal(t, ": x\n: y\nevent: ab\n\n", w.Body.String())
+	assert.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+}
+
+type sseTestCodec struct {
+	json.Core
+}
+
+func (c sseTestCodec) Marshal(v any) ([]byte, error) {
+	return []byte(`"custom"`), nil
+}
+
+func TestSSEventRenderCodec(t *testing.T) {
+	json.Use(sseTestCodec{Core: json.Default()})
+	defer json.Use(nil)
+
+	w := httptest.NewRecorder()
+	assert.NoError(t, render.SSEvent{Data: map[string]int{"x": 1}}.Render(w))
+	assert.Equal(t, "data: \"custom\"\n\n", w.Body.String())
+}
@@ -0,0 +1,247 @@
+package dawn
+
+import (
+	"dawn/render"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultSSEBufferSize = 16
+	defaultSSEHistoryTTL = 5 * time.Minute
+)
+
+// SSEOverflowPolicy decides what happens to a subscriber whose buffer is full.
+type SSEOverflowPolicy uint8
+
+const (
+	// SSEDropSubscriber closes the stream of a slow subscriber. The client reconnects with its
+	// Last-Event-ID and catches up from the topic history.
+	SSEDropSubscriber SSEOverflowPolicy = iota
+	// SSEDropEvent skips the event for a slow subscriber and keeps its stream open.
+	SSEDropEvent
+)
+
+// SSEHubConfig defines the config for SSEHub.
+type SSEHubConfig struct {
+	// BufferSize is the number of events queued per subscriber, defaults to 16.
+	BufferSize int
+	// HistorySize is the number of events kept per topic for Last-Event-ID replay,
+	// zero disables replay.
+	HistorySize int
+	// HistoryTTL is how long the history of a topic without subscribers is kept after its
+	// last event or subscriber, defaults to 5 minutes. Expired histories are evicted when
+	// events are published, so short-lived topics such as one per job do not accumulate.
+	HistoryTTL time.Duration
+	// KeepAlive is the idle interval after which a keep-alive comment is sent, zero disables it.
+	KeepAlive time.Duration
+	// Policy is applied to subscribers whose buffer is full.
+	Policy SSEOverflowPolicy
+}
+
+// SSEHub fans out Server-Sent Events published to topics to all the subscribed clients.
+type SSEHub struct {
+	config SSEHubConfig
+
+	mu        sync.Mutex
+	seq       uint64
+	closed    bool
+	topics    map[string]map[*sseSubscriber]struct{}
+	history   map[string]*sseHistory
+	lastSweep time.Time
+}
+
+// sseHistory is the history of a topic, touched is the time of its last event or subscriber.
+type sseHistory struct {
+	events  *ReplayBuffer
+	touched time.Time
+}
+
+type sseSubscriber struct {
+	events chan render.SSEvent
+	topics []string
+	closed bool
+}
+
+// NewSSEHub returns a SSEHub which is closed when the engine shuts down.
+func (e *Engine) NewSSEHub(config SSEHubConfig) *SSEHub {
+	if config.BufferSize <= 0 {
+		config.BufferSize = defaultSSEBufferSize
+	}
+	if config.HistoryTTL <= 0 {
+		config.HistoryTTL = defaultSSEHistoryTTL
+	}
+	h := &SSEHub{
+		config:  config,
+		topics:  make(map[string]map[*sseSubscriber]struct{}),
+		history: make(map[string]*sseHistory),
+	}
+	e.RegisterOnShutdown(h.Close)
+	return h
+}
+
+// Publish sends the event to every subscriber of topic and records it in the topic history.
+// The hub assigns sequential event IDs, so Last-Event-ID can be resumed across topics.
+// It returns the event as sent.
+func (h *SSEHub) Publish(topic string, event render.SSEvent) render.SSEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return event
+	}
+
+	h.seq++
+	event.ID = strconv.FormatUint(h.seq, 10)
+
+	if h.config.HistorySize > 0 {
+		now := time.Now()
+		hist, ok := h.history[topic]
+		if !ok {
+			hist = &sseHistory{events: NewReplayBuffer(h.config.HistorySize)}
+			h.history[topic] = hist
+		}
+		hist.events.Add(event)
+		hist.touched = now
+		h.evict(now)
+	}
+
+	for sub := range h.topics[topic] {
+		select {
+		case sub.events <- event:
+		default:
+			if h.config.Policy == SSEDropSubscriber {
+				h.remove(sub)
+			}
+		}
+	}
+	return event
+}
+
+// Subscribe streams the events published to topics to the client of c. Events after the
+// client's Last-Event-ID are replayed first. It blocks until the client disconnects, the
+// subscriber is dropped or the hub is closed, and returns true if the client disconnected.
+func (h *SSEHub) Subscribe(c *Context, topics ...string) bool {
+	sub := &sseSubscriber{
+		events: make(chan render.SSEvent, h.config.BufferSize),
+		topics: topics,
+	}
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return false
+	}
+	for _, topic := range topics {
+		subs, ok := h.topics[topic]
+		if !ok {
+			subs = make(map[*sseSubscriber]struct{})
+			h.topics[topic] = subs
+		}
+		subs[sub] = struct{}{}
+	}
+	// taken under the lock, so no event is both replayed and queued.
+	replay := h.replay(c.LastEventID(), topics)
+	h.mu.Unlock()
+
+	defer func() {
+		h.mu.Lock()
+		h.remove(sub)
+		h.mu.Unlock()
+	}()
+
+	for _, event := range replay {
+		c.Render(-1, event)
+	}
+	return c.SSEventStream(sub.events, h.config.KeepAlive)
+}
+
+// Subscribers returns the number of subscribers of topic.
+func (h *SSEHub) Subscribers(topic string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.topics[topic])
+}
+
+// Close ends all the streams, later calls to Subscribe return immediately.
+func (h *SSEHub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for _, subs := range h.topics {
+		for sub := range subs {
+			h.remove(sub)
+		}
+	}
+}
+
+// remove unsubscribes sub from all its topics and ends its stream, h.mu must be held.
+func (h *SSEHub) remove(sub *sseSubscriber) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	close(sub.events)
+
+	for _, topic := range sub.topics {
+		if subs := h.topics[topic]; subs != nil {
+			delete(subs, sub)
+			if len(subs) == 0 {
+				delete(h.topics, topic)
+			}
+		}
+		// the client may reconnect, so the history is kept for HistoryTTL from now.
+		if hist := h.history[topic]; hist != nil {
+			hist.touched = time.Now()
+		}
+	}
+}
+
+// evict removes the histories of the topics without subscribers which were not touched for
+// HistoryTTL. It runs at most once per HistoryTTL, h.mu must be held.
+func (h *SSEHub) evict(now time.Time) {
+	if now.Sub(h.lastSweep) < h.config.HistoryTTL {
+		return
+	}
+	h.lastSweep = now
+	for topic, hist := range h.history {
+		if len(h.topics[topic]) == 0 && now.Sub(hist.touched) >= h.config.HistoryTTL {
+			delete(h.history, topic)
+		}
+	}
+}
+
+// replay returns the events of topics published after lastID ordered by ID, h.mu must be held.
+func (h *SSEHub) replay(lastID string, topics []string) []render.SSEvent {
+	if lastID == "" {
+		return nil
+	}
+	last, err := strconv.ParseUint(lastID, 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	var events []render.SSEvent
+	seen := make(map[string]bool, len(topics))
+	for _, topic := range topics {
+		hist, ok := h.history[topic]
+		if !ok || seen[topic] {
+			continue
+		}
+		seen[topic] = true
+		for _, event := range hist.events.all() {
+			if id, _ := strconv.ParseUint(event.ID, 10, 64); id > last {
+				events = append(events, event)
+			}
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		a, _ := strconv.ParseUint(events[i].ID, 10, 64)
+		b, _ := strconv.ParseUint(events[j].ID, 10, 64)
+		return a < b
+	})
+	return events
+}
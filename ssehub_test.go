@@ -0,0 +1,120 @@
+package dawn
+
+import (
+	"context"
+	"dawn/render"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// waitSubscribers waits until topic has n subscribers.
+func waitSubscribers(t *testing.T, h *SSEHub, topic string, n int) {
+	deadline := time.Now().Add(time.Second)
+	for h.Subscribers(topic) != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("topic %q has %d subscribers, want %d", topic, h.Subscribers(topic), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSSEHubPublishAndReplay(t *testing.T) {
+	e := New()
+	h := e.NewSSEHub(SSEHubConfig{HistorySize: 10})
+	assert.Equal(t, "1", h.Publish("a", render.SSEvent{Data: "1"}).ID)
+	h.Publish("b", render.SSEvent{Data: "2"})
+	h.Publish("a", render.SSEvent{Data: "3"})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Last-Event-ID", "1")
+	c, w := createTestContext(req)
+	done := make(chan bool)
+	go func() { done <- h.Subscribe(c, "a", "b") }()
+	waitSubscribers(t, h, "a", 1)
+
+	h.Publish("b", render.SSEvent{Data: "4"})
+	h.Publish("c", render.SSEvent{Data: "5"})
+	time.Sleep(20 * time.Millisecond)
+	e.Shutdown()
+
+	assert.False(t, <-done)
+	assert.Equal(t, "id: 2\ndata: 2\n\nid: 3\ndata: 3\n\nid: 4\ndata: 4\n\n", w.Body.String())
+	assert.Equal(t, 0, h.Subscribers("a"))
+
+	// a closed hub does not accept subscribers.
+	c, _ = createTestContext(httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.False(t, h.Subscribe(c, "a"))
+}
+
+func TestSSEHubDropSlowSubscriber(t *testing.T) {
+	h := New().NewSSEHub(SSEHubConfig{BufferSize: 1})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c, _ := createTestContext(httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+
+	done := make(chan bool)
+	go func() { done <- h.Subscribe(c, "x") }()
+	waitSubscribers(t, h, "x", 1)
+	for i := 0; i < 100; i++ {
+		h.Publish("x", render.SSEvent{Data: i})
+	}
+	assert.False(t, <-done)
+	assert.Equal(t, 0, h.Subscribers("x"))
+}
+
+func TestSSEHubDropEvent(t *testing.T) {
+	h := New().NewSSEHub(SSEHubConfig{BufferSize: 1, Policy: SSEDropEvent})
+	ctx, cancel := context.WithCancel(context.Background())
+	c, _ := createTestContext(httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+
+	done := make(chan bool)
+	go func() { done <- h.Subscribe(c, "x") }()
+	waitSubscribers(t, h, "x", 1)
+	for i := 0; i < 100; i++ {
+		h.Publish("x", render.SSEvent{Data: i})
+	}
+	assert.Equal(t, 1, h.Subscribers("x"))
+	cancel()
+	assert.True(t, <-done)
+}
+
+func TestSSEHubHistoryEviction(t *testing.T) {
+	h := New().NewSSEHub(SSEHubConfig{HistorySize: 4, HistoryTTL: 20 * time.Millisecond})
+	for i := 0; i < 50; i++ {
+		h.Publish("job-"+string(rune('a'+i%26))+string(rune('a'+i/26)), render.SSEvent{Data: i})
+	}
+	assert.Len(t, h.history, 50)
+
+	// the history of a topic with a subscriber is kept.
+	ctx, cancel := context.WithCancel(context.Background())
+	c, _ := createTestContext(httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+	done := make(chan bool)
+	go func() { done <- h.Subscribe(c, "job-aa") }()
+	waitSubscribers(t, h, "job-aa", 1)
+
+	time.Sleep(30 * time.Millisecond)
+	h.Publish("job-new", render.SSEvent{Data: "new"})
+	h.mu.Lock()
+	assert.Len(t, h.history, 2)
+	assert.Contains(t, h.history, "job-aa")
+	assert.Contains(t, h.history, "job-new")
+	h.mu.Unlock()
+
+	// a reconnecting client still finds the history within the TTL.
+	cancel()
+	<-done
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Last-Event-ID", "0")
+	ctx, cancel = context.WithCancel(context.Background())
+	c, w := createTestContext(req.WithContext(ctx))
+	go func() { done <- h.Subscribe(c, "job-aa") }()
+	waitSubscribers(t, h, "job-aa", 1)
+	cancel()
+	<-done
+	assert.True(t, strings.HasPrefix(w.Body.String(), "id: 1\ndata: 0\n\n"))
+}
@@ -0,0 +1,315 @@
+package dawn
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// TimeoutConfig defines the config for the Timeout middleware.
+type TimeoutConfig struct {
+	// Timeout is the time the remaining handlers have to complete the request.
+	Timeout time.Duration
+
+	// Status is the status code of the response written when the timeout expires,
+	// defaults to 503 Service Unavailable. 504 Gateway Timeout suits proxies.
+	Status int
+
+	// ContentType and Body are the content of the timeout response, the body defaults to
+	// the status text as plain text.
+	ContentType string
+	Body        []byte
+}
+
+// Timeout returns a middleware which runs the remaining handlers under a deadline of d,
+// see TimeoutWithConfig.
+func Timeout(d time.Duration) HandlerFunc {
+	return TimeoutWithConfig(TimeoutConfig{Timeout: d})
+}
+
+// TimeoutWithConfig returns a middleware which runs the remaining handlers under a deadline.
+// When the deadline expires, the request context is canceled and the timeout response is
+// written, unless the handlers already started writing the response, e.g. a stream, which is
+// then cut off. Later writes of the handlers fail with http.ErrHandlerTimeout. The handlers
+// run in the calling goroutine, so the middleware returns once they have returned, and the
+// Context is never used concurrently.
+//
+// Used in a route after another Timeout middleware, it overrides the timeout of the former
+// for this route, counted from the moment the former started:
+//
+//	router.Use(dawn.Timeout(5 * time.Second))
+//	router.GET("/export", dawn.Timeout(time.Minute), export)
+func TimeoutWithConfig(config TimeoutConfig) HandlerFunc {
+	assert1(config.Timeout > 0, "timeout must be positive")
+	if config.Status == 0 {
+		config.Status = http.StatusServiceUnavailable
+	}
+	if config.Body == nil {
+		config.Body = []byte(http.StatusText(config.Status))
+		if config.ContentType == "" {
+			config.ContentType = MIMEPlain + "; charset=utf-8"
+		}
+	}
+
+	return func(c *Context) {
+		if tw, ok := c.Writer.(*timeoutWriter); ok {
+			tw.reset(config)
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithCancel(c.Request.Context())
+		defer cancel()
+
+		tw := &timeoutWriter{
+			ResponseWriter: c.Writer,
+			header:         c.Writer.Header().Clone(),
+			start:          time.Now(),
+			cancel:         cancel,
+		}
+		tw.reset(config)
+
+		req := c.Request
+		c.Request = req.WithContext(&timeoutContext{Context: ctx, tw: tw})
+		c.Writer = tw
+
+		c.Next()
+
+		timedOut := tw.finish()
+		c.Writer = tw.ResponseWriter
+		c.Request = req
+		if timedOut {
+			_ = c.Error(http.ErrHandlerTimeout)
+			c.Abort()
+		}
+	}
+}
+
+// timeoutWriter serializes the writes of the handlers with the timeout response. The handlers
+// write the headers to their own map, which is copied once the response is committed.
+type timeoutWriter struct {
+	ResponseWriter
+	header http.Header
+	start  time.Time
+	cancel context.CancelFunc
+
+	mu       sync.Mutex
+	config   TimeoutConfig
+	deadline time.Time
+	timer    *time.Timer
+	timedOut bool
+	done     bool
+}
+
+var _ ResponseWriter = (*timeoutWriter)(nil)
+
+// reset (re)arms the timer to expire config.Timeout after the start of the first middleware.
+func (tw *timeoutWriter) reset(config TimeoutConfig) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut || tw.done {
+		return
+	}
+	tw.config = config
+	tw.deadline = tw.start.Add(config.Timeout)
+	if tw.timer != nil {
+		tw.timer.Stop()
+	}
+	tw.timer = time.AfterFunc(time.Until(tw.deadline), tw.timeout)
+}
+
+func (tw *timeoutWriter) timeout() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut || tw.done || time.Now().Before(tw.deadline) {
+		return
+	}
+	tw.timedOut = true
+	tw.cancel()
+
+	if tw.ResponseWriter.Written() {
+		return
+	}
+	// the hooks belong to the response of the handlers, not to the timeout response.
+	if hooks, ok := tw.ResponseWriter.(writeHooks); ok {
+		hooks.takeBeforeWrite()
+	}
+	if tw.config.ContentType != "" {
+		tw.ResponseWriter.Header().Set("Content-Type", tw.config.ContentType)
+	}
+	tw.ResponseWriter.WriteHeader(tw.config.Status)
+	_, _ = tw.ResponseWriter.Write(tw.config.Body)
+	// the handlers may keep running for long, the client gets the response now.
+	tw.ResponseWriter.Flush()
+}
+
+// runBeforeWrite runs the hooks of the underlying writer before the handlers commit the
+// response. They run unlocked as they may use the writer themselves, e.g. to set a cookie.
+func (tw *timeoutWriter) runBeforeWrite() {
+	for _, f := range tw.takeBeforeWrite() {
+		f()
+	}
+}
+
+func (tw *timeoutWriter) takeBeforeWrite() []func() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return nil
+	}
+	if hooks, ok := tw.ResponseWriter.(writeHooks); ok {
+		return hooks.takeBeforeWrite()
+	}
+	return nil
+}
+
+// finish stops the timer once the handlers returned and reports whether the timeout expired.
+func (tw *timeoutWriter) finish() bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	tw.done = true
+	tw.timer.Stop()
+	if !tw.timedOut {
+		tw.syncHeader()
+	}
+	return tw.timedOut
+}
+
+// syncHeader copies the headers of the handlers to the response, tw.mu must be held.
+func (tw *timeoutWriter) syncHeader() {
+	if tw.ResponseWriter.Written() {
+		return
+	}
+	dst := tw.ResponseWriter.Header()
+	for k := range dst {
+		if _, ok := tw.header[k]; !ok {
+			delete(dst, k)
+		}
+	}
+	for k, v := range tw.header {
+		dst[k] = v
+	}
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if !tw.timedOut {
+		tw.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (tw *timeoutWriter) WriteHeaderNow() {
+	tw.runBeforeWrite()
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if !tw.timedOut {
+		tw.syncHeader()
+		tw.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (tw *timeoutWriter) Write(data []byte) (int, error) {
+	tw.runBeforeWrite()
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	tw.syncHeader()
+	return tw.ResponseWriter.Write(data)
+}
+
+func (tw *timeoutWriter) WriteString(s string) (int, error) {
+	tw.runBeforeWrite()
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	tw.syncHeader()
+	return tw.ResponseWriter.WriteString(s)
+}
+
+func (tw *timeoutWriter) Status() int {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	return tw.ResponseWriter.Status()
+}
+
+func (tw *timeoutWriter) Size() int {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	return tw.ResponseWriter.Size()
+}
+
+func (tw *timeoutWriter) Written() bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	return tw.timedOut || tw.ResponseWriter.Written()
+}
+
+// Hijack implements the http.Hijacker interface, the timeout no longer applies to the
+// hijacked connection.
+func (tw *timeoutWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return nil, nil, http.ErrHandlerTimeout
+	}
+	tw.syncHeader()
+	tw.timer.Stop()
+	return tw.ResponseWriter.Hijack()
+}
+
+// Flush implements the http.Flusher interface.
+func (tw *timeoutWriter) Flush() {
+	tw.runBeforeWrite()
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if !tw.timedOut {
+		tw.syncHeader()
+		tw.ResponseWriter.Flush()
+	}
+}
+
+// timeoutContext reports the deadline of the Timeout middleware, which may be overridden by
+// a route after the context was created.
+type timeoutContext struct {
+	context.Context
+	tw *timeoutWriter
+}
+
+func (ctx *timeoutContext) Deadline() (time.Time, bool) {
+	ctx.tw.mu.Lock()
+	deadline := ctx.tw.deadline
+	ctx.tw.mu.Unlock()
+
+	if parent, ok := ctx.Context.Deadline(); ok && parent.Before(deadline) {
+		return parent, true
+	}
+	return deadline, true
+}
+
+func (ctx *timeoutContext) Err() error {
+	err := ctx.Context.Err()
+	if err == nil {
+		return nil
+	}
+	ctx.tw.mu.Lock()
+	defer ctx.tw.mu.Unlock()
+	if ctx.tw.timedOut {
+		return context.DeadlineExceeded
+	}
+	return err
+}
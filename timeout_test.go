@@ -0,0 +1,129 @@
+package dawn
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveTimeout runs handlers on a new context whose response already has a header set.
+func serveTimeout(handlers ...HandlerFunc) (*Context, *httptest.ResponseRecorder) {
+	c, w := createTestContext(httptest.NewRequest(http.MethodGet, "/", nil))
+	c.Header("X-Request-Id", "1")
+	serveTestContext(c, handlers...)
+	return c, w
+}
+
+func TestTimeoutCompleted(t *testing.T) {
+	c, w := serveTimeout(Timeout(time.Second), func(c *Context) {
+		deadline, ok := c.Request.Context().Deadline()
+		assert.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(time.Second), deadline, 100*time.Millisecond)
+		c.Header("X-Handler", "1")
+		c.Data(http.StatusCreated, MIMEPlain, []byte("created"))
+	})
+
+	assert.Empty(t, c.Errors)
+	assert.False(t, c.IsAborted())
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "created", w.Body.String())
+	assert.Equal(t, "1", w.Header().Get("X-Request-Id"))
+	assert.Equal(t, "1", w.Header().Get("X-Handler"))
+	assert.Same(t, &c.writermem, c.Writer)
+}
+
+func TestTimeoutExpired(t *testing.T) {
+	var writeErr, ctxErr error
+	c, w := serveTimeout(Timeout(20*time.Millisecond), func(c *Context) {
+		c.Header("X-Handler", "1")
+		<-c.Request.Context().Done()
+		ctxErr = c.Request.Context().Err()
+		_, writeErr = c.Writer.Write([]byte("late"))
+	})
+
+	assert.ErrorIs(t, ctxErr, context.DeadlineExceeded)
+	assert.ErrorIs(t, writeErr, http.ErrHandlerTimeout)
+	assert.True(t, c.IsAborted())
+	assert.True(t, c.Errors.Is(http.ErrHandlerTimeout))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "Service Unavailable", w.Body.String())
+	assert.Equal(t, MIMEPlain+"; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Empty(t, w.Header().Get("X-Handler"))
+}
+
+func TestTimeoutWithConfig(t *testing.T) {
+	config := TimeoutConfig{
+		Timeout:     10 * time.Millisecond,
+		Status:      http.StatusGatewayTimeout,
+		ContentType: MIMEJSON,
+		Body:        []byte(`{"error":"timeout"}`),
+	}
+	_, w := serveTimeout(TimeoutWithConfig(config), func(c *Context) {
+		time.Sleep(40 * time.Millisecond)
+		c.Data(http.StatusOK, MIMEPlain, []byte("late"))
+	})
+
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.Equal(t, `{"error":"timeout"}`, w.Body.String())
+	assert.Equal(t, MIMEJSON, w.Header().Get("Content-Type"))
+
+	assert.Panics(t, func() { Timeout(0) })
+}
+
+func TestTimeoutOverride(t *testing.T) {
+	c, w := serveTimeout(Timeout(10*time.Millisecond), Timeout(time.Second), func(c *Context) {
+		time.Sleep(40 * time.Millisecond)
+		c.Data(http.StatusOK, MIMEPlain, []byte("extended"))
+	})
+	assert.Empty(t, c.Errors)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "extended", w.Body.String())
+}
+
+func TestTimeoutStreamCut(t *testing.T) {
+	c, w := serveTimeout(Timeout(30*time.Millisecond), func(c *Context) {
+		for c.Request.Context().Err() == nil {
+			_, _ = c.Writer.WriteString("x")
+			c.Writer.Flush()
+			time.Sleep(5 * time.Millisecond)
+		}
+	})
+	// the response was started, so it is cut off instead of replaced.
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEmpty(t, w.Body.String())
+	assert.NotContains(t, w.Body.String(), "Service Unavailable")
+	assert.True(t, c.Errors.Is(http.ErrHandlerTimeout))
+}
+
+func TestTimeoutBlockedHandler(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		c := &Context{Request: req, engine: New(), index: -1}
+		c.writermem.reset(w)
+		c.Writer = &c.writermem
+		serveTestContext(c, Timeout(20*time.Millisecond), func(c *Context) {
+			// ignores the canceled context, e.g. blocked in a call without one.
+			<-release
+		})
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	client := &http.Client{Timeout: 2 * time.Second}
+	start := time.Now()
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	body, err := io.ReadAll(io.LimitReader(resp.Body, int64(len("Service Unavailable"))))
+	require.NoError(t, err)
+	assert.Equal(t, "Service Unavailable", string(body))
+}
@@ -0,0 +1,71 @@
+package dawn
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// H is a shortcut for map[string]any
+type H map[string]any
+
+func assert1(guard bool, text string) {
+	if !guard {
+		panic(text)
+	}
+}
+
+func chooseData(custom, wildcard any) any {
+	if custom != nil {
+		return custom
+	}
+	if wildcard != nil {
+		return wildcard
+	}
+	panic("negotiation config is invalid")
+}
+
+func filterFlags(content string) string {
+	for i, char := range content {
+		if char == ' ' || char == ';' {
+			return content[:i]
+		}
+	}
+	return content
+}
+
+// mediaType returns the lowercase media type of content without its parameters.
+func mediaType(content string) string {
+	content, _, _ = strings.Cut(content, ";")
+	return strings.ToLower(strings.TrimSpace(content))
+}
+
+// dataETag returns a strong entity tag for the given response body.
+func dataETag(data []byte) string {
+	h := fnv.New64a()
+	_, _ = h.Write(data)
+	return fmt.Sprintf(`"%x-%x"`, len(data), h.Sum64())
+}
+
+// fileETag returns a weak entity tag derived from the file's size and modification time.
+func fileETag(fi os.FileInfo) string {
+	return fmt.Sprintf(`W/"%x-%x"`, fi.Size(), fi.ModTime().UnixNano())
+}
+
+// https://stackoverflow.com/questions/53069040/checking-a-string-contains-only-ascii-characters
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
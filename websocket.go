@@ -0,0 +1,184 @@
+package dawn
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// websocketGUID is the magic value of the Sec-WebSocket-Accept computation (RFC 6455, Section 1.3).
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+const defaultWebSocketReadLimit = 32 << 20 // 32MB
+
+var (
+	// ErrWebSocketHandshake is returned by UpgradeWebSocket when the request is not a valid
+	// WebSocket opening handshake.
+	ErrWebSocketHandshake = errors.New("dawn: invalid websocket handshake")
+
+	// ErrWebSocketOrigin is returned by UpgradeWebSocket when the request Origin is not allowed.
+	ErrWebSocketOrigin = errors.New("dawn: websocket origin not allowed")
+)
+
+// WebSocketOptions defines the options of UpgradeWebSocket.
+type WebSocketOptions struct {
+	// Subprotocols lists the supported subprotocols in order of preference.
+	Subprotocols []string
+
+	// AllowedOrigins lists the origins, e.g. "https://example.com", allowed besides the
+	// request's own host, "*" allows any origin.
+	AllowedOrigins []string
+
+	// CheckOrigin replaces the origin check of AllowedOrigins when set.
+	CheckOrigin func(c *Context) bool
+
+	// ReadLimit is the maximum size in bytes of a received message after decompression,
+	// defaults to 32MB.
+	ReadLimit int64
+
+	// EnableCompression negotiates permessage-deflate (RFC 7692) when the client offers it.
+	EnableCompression bool
+}
+
+// UpgradeWebSocket performs the WebSocket opening handshake over c.Writer.Hijack and returns
+// the connection. Headers already set on c.Writer, e.g. cookies, are sent with the handshake
+// response. On failure the request is aborted with the matching status code.
+func (c *Context) UpgradeWebSocket(opts WebSocketOptions) (*WebSocketConn, error) {
+	if c.Request.Method != http.MethodGet || !c.IsWebsocket() {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return nil, ErrWebSocketHandshake
+	}
+	if c.requestHeader("Sec-WebSocket-Version") != "13" {
+		c.Header("Sec-WebSocket-Version", "13")
+		c.AbortWithStatus(http.StatusUpgradeRequired)
+		return nil, ErrWebSocketHandshake
+	}
+	key := c.requestHeader("Sec-WebSocket-Key")
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return nil, ErrWebSocketHandshake
+	}
+
+	checkOrigin := opts.CheckOrigin
+	if checkOrigin == nil {
+		checkOrigin = func(c *Context) bool {
+			return originAllowed(c.Request, opts.AllowedOrigins)
+		}
+	}
+	if !checkOrigin(c) {
+		c.AbortWithStatus(http.StatusForbidden)
+		return nil, ErrWebSocketOrigin
+	}
+
+	subprotocol := selectSubprotocol(c.Request.Header.Values("Sec-WebSocket-Protocol"), opts.Subprotocols)
+	compress := opts.EnableCompression &&
+		acceptsPerMessageDeflate(c.Request.Header.Values("Sec-WebSocket-Extensions"))
+
+	var b strings.Builder
+	b.WriteString("HTTP/1.1 101 Switching Protocols\r\n")
+	b.WriteString("Upgrade: websocket\r\nConnection: Upgrade\r\n")
+	b.WriteString("Sec-WebSocket-Accept: " + websocketAccept(key) + "\r\n")
+	if subprotocol != "" {
+		b.WriteString("Sec-WebSocket-Protocol: " + subprotocol + "\r\n")
+	}
+	if compress {
+		b.WriteString("Sec-WebSocket-Extensions: permessage-deflate; server_no_context_takeover; client_no_context_takeover\r\n")
+	}
+	for k, vs := range c.Writer.Header() {
+		switch k {
+		case "Upgrade", "Connection", "Sec-Websocket-Accept", "Sec-Websocket-Protocol", "Sec-Websocket-Extensions":
+			continue
+		}
+		for _, v := range vs {
+			b.WriteString(k + ": " + v + "\r\n")
+		}
+	}
+	b.WriteString("\r\n")
+
+	c.Writer.WriteHeader(http.StatusSwitchingProtocols)
+	netConn, brw, err := c.Writer.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := netConn.Write([]byte(b.String())); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+
+	readLimit := opts.ReadLimit
+	if readLimit <= 0 {
+		readLimit = defaultWebSocketReadLimit
+	}
+	return newWebSocketConn(netConn, brw.Reader, subprotocol, compress, readLimit), nil
+}
+
+func websocketAccept(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key))
+	h.Write([]byte(websocketGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// originAllowed allows requests without Origin, from the request's own host and from allowed.
+func originAllowed(req *http.Request, allowed []string) bool {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	for _, o := range allowed {
+		if o == "*" || strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, req.Host)
+}
+
+// selectSubprotocol returns the first of the server's subprotocols offered by the client.
+func selectSubprotocol(offered []string, supported []string) string {
+	for _, s := range supported {
+		for _, header := range offered {
+			for _, o := range strings.Split(header, ",") {
+				if strings.TrimSpace(o) == s {
+					return s
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// acceptsPerMessageDeflate reports whether the client offers permessage-deflate with
+// parameters the server can honour. Limiting the server window is not supported by
+// compress/flate, so such offers are declined.
+func acceptsPerMessageDeflate(headers []string) bool {
+	for _, header := range headers {
+		for _, ext := range splitQuoted(header, ',') {
+			params := splitQuoted(ext, ';')
+			if len(params) == 0 || !strings.EqualFold(params[0], "permessage-deflate") {
+				continue
+			}
+			ok := true
+			for _, param := range params[1:] {
+				k, v, _ := strings.Cut(param, "=")
+				switch strings.ToLower(strings.TrimSpace(k)) {
+				case "server_no_context_takeover", "client_no_context_takeover", "client_max_window_bits":
+				case "server_max_window_bits":
+					ok = ok && strings.Trim(strings.TrimSpace(v), `"`) == "15"
+				default:
+					ok = false
+				}
+			}
+			if ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,513 @@
+package dawn
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Message types of WebSocketConn, they are the opcodes of RFC 6455.
+const (
+	WSTextMessage   = 1
+	WSBinaryMessage = 2
+	WSCloseMessage  = 8
+	WSPingMessage   = 9
+	WSPongMessage   = 10
+)
+
+const wsContinuation = 0
+
+// Close codes defined in RFC 6455, Section 7.4.1.
+const (
+	WSCloseNormalClosure           = 1000
+	WSCloseGoingAway               = 1001
+	WSCloseProtocolError           = 1002
+	WSCloseUnsupportedData         = 1003
+	WSCloseNoStatusReceived        = 1005
+	WSCloseAbnormalClosure         = 1006
+	WSCloseInvalidFramePayloadData = 1007
+	WSClosePolicyViolation         = 1008
+	WSCloseMessageTooBig           = 1009
+	WSCloseMandatoryExtension      = 1010
+	WSCloseInternalServerErr       = 1011
+)
+
+const (
+	wsMaxControlPayload = 125
+	wsFinBit            = 1 << 7
+	wsRsv1Bit           = 1 << 6
+	wsRsv2Bit           = 1 << 5
+	wsRsv3Bit           = 1 << 4
+	wsMaskBit           = 1 << 7
+
+	// wsWriteChunk is the payload size from which compressed streams are sent as a fragment.
+	wsWriteChunk = 4096
+)
+
+var (
+	// ErrWebSocketClosed is returned when writing to a connection after its close frame was sent.
+	ErrWebSocketClosed = errors.New("dawn: websocket connection closed")
+
+	// ErrWebSocketReadLimit is returned when a received message exceeds the read limit.
+	ErrWebSocketReadLimit = errors.New("dawn: websocket read limit exceeded")
+
+	// deflateTail completes a raw deflate stream stripped of its sync flush marker (RFC 7692).
+	deflateTail = []byte{0x00, 0x00, 0xff, 0xff, 0x01, 0x00, 0x00, 0xff, 0xff}
+
+	flateWriterPool sync.Pool
+)
+
+// CloseError is returned by ReadMessage when the peer closed the connection.
+type CloseError struct {
+	Code int
+	Text string
+}
+
+func (e *CloseError) Error() string {
+	return "dawn: websocket closed with code " + strconv.Itoa(e.Code) + ": " + e.Text
+}
+
+// WebSocketConn is a WebSocket connection returned by Context.UpgradeWebSocket. One goroutine
+// may read while others write, writes of whole messages are serialized.
+type WebSocketConn struct {
+	conn        net.Conn
+	br          *bufio.Reader
+	subprotocol string
+	compress    bool
+	readLimit   int64
+
+	// msgMu serializes data messages, frameMu frames. Control frames may be sent between the
+	// fragments of a data message, so they only take frameMu.
+	msgMu     sync.Mutex
+	frameMu   sync.Mutex
+	closeSent bool
+
+	pingHandler func(appData []byte) error
+	pongHandler func(appData []byte) error
+}
+
+func newWebSocketConn(conn net.Conn, br *bufio.Reader, subprotocol string, compress bool, readLimit int64) *WebSocketConn {
+	ws := &WebSocketConn{
+		conn:        conn,
+		br:          br,
+		subprotocol: subprotocol,
+		compress:    compress,
+		readLimit:   readLimit,
+	}
+	ws.pingHandler = func(appData []byte) error {
+		err := ws.writeFrame(true, false, WSPongMessage, appData)
+		if errors.Is(err, ErrWebSocketClosed) {
+			return nil
+		}
+		return err
+	}
+	ws.pongHandler = func([]byte) error { return nil }
+	return ws
+}
+
+// Subprotocol returns the negotiated subprotocol.
+func (ws *WebSocketConn) Subprotocol() string {
+	return ws.subprotocol
+}
+
+// Compressed reports whether permessage-deflate was negotiated.
+func (ws *WebSocketConn) Compressed() bool {
+	return ws.compress
+}
+
+// RemoteAddr returns the remote network address.
+func (ws *WebSocketConn) RemoteAddr() net.Addr {
+	return ws.conn.RemoteAddr()
+}
+
+// SetReadLimit sets the maximum size in bytes of a received message.
+func (ws *WebSocketConn) SetReadLimit(limit int64) {
+	ws.readLimit = limit
+}
+
+// SetReadDeadline sets the deadline of future reads, a zero value disables it.
+func (ws *WebSocketConn) SetReadDeadline(t time.Time) error {
+	return ws.conn.SetReadDeadline(t)
+}
+
+// SetWriteDeadline sets the deadline of future writes, a zero value disables it.
+func (ws *WebSocketConn) SetWriteDeadline(t time.Time) error {
+	return ws.conn.SetWriteDeadline(t)
+}
+
+// SetPingHandler sets the handler of received pings, the default one answers with a pong.
+// Handlers are called from ReadMessage.
+func (ws *WebSocketConn) SetPingHandler(h func(appData []byte) error) {
+	ws.pingHandler = h
+}
+
+// SetPongHandler sets the handler of received pongs. Handlers are called from ReadMessage.
+func (ws *WebSocketConn) SetPongHandler(h func(appData []byte) error) {
+	ws.pongHandler = h
+}
+
+// Ping sends a ping with appData of at most 125 bytes.
+func (ws *WebSocketConn) Ping(appData []byte) error {
+	return ws.writeFrame(true, false, WSPingMessage, appData)
+}
+
+// WriteMessage sends data as a single message of the given type.
+func (ws *WebSocketConn) WriteMessage(messageType int, data []byte) error {
+	w, err := ws.NextWriter(messageType)
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(data); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// NextWriter returns a writer for the next message of the given type, the message is sent
+// fragmented as it is written and ends when the writer is closed. Other data messages wait
+// until the writer is closed.
+func (ws *WebSocketConn) NextWriter(messageType int) (io.WriteCloser, error) {
+	if messageType != WSTextMessage && messageType != WSBinaryMessage {
+		return nil, fmt.Errorf("dawn: invalid websocket message type %d", messageType)
+	}
+
+	ws.msgMu.Lock()
+	w := &wsMessageWriter{ws: ws, opcode: messageType}
+	if ws.compress {
+		w.flate = getFlateWriter(&w.pending)
+	}
+	return w, nil
+}
+
+// ReadMessage returns the next text or binary message, reassembled from its fragments and
+// decompressed. Control frames are handled while reading, a close frame is answered and
+// returned as *CloseError.
+func (ws *WebSocketConn) ReadMessage() (messageType int, data []byte, err error) {
+	var (
+		message    bytes.Buffer
+		compressed bool
+	)
+	for {
+		fin, rsv1, opcode, payload, err := ws.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		switch opcode {
+		case WSPingMessage:
+			if err := ws.pingHandler(payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case WSPongMessage:
+			if err := ws.pongHandler(payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case WSCloseMessage:
+			return 0, nil, ws.handleClose(payload)
+		case WSTextMessage, WSBinaryMessage:
+			if messageType != 0 {
+				return 0, nil, ws.fail(WSCloseProtocolError, "new message before the previous one ended")
+			}
+			messageType, compressed = opcode, rsv1
+		case wsContinuation:
+			if messageType == 0 || rsv1 {
+				return 0, nil, ws.fail(WSCloseProtocolError, "unexpected continuation frame")
+			}
+		}
+
+		if int64(message.Len()+len(payload)) > ws.readLimit {
+			return 0, nil, ws.failWith(WSCloseMessageTooBig, "message too big", ErrWebSocketReadLimit)
+		}
+		message.Write(payload)
+		if !fin {
+			continue
+		}
+
+		data = message.Bytes()
+		if compressed {
+			if data, err = ws.inflate(data); err != nil {
+				return 0, nil, err
+			}
+		}
+		if messageType == WSTextMessage && !utf8.Valid(data) {
+			return 0, nil, ws.fail(WSCloseInvalidFramePayloadData, "invalid UTF-8 in text message")
+		}
+		return messageType, data, nil
+	}
+}
+
+// CloseWithCode sends a close frame with the given code and reason and closes the connection.
+func (ws *WebSocketConn) CloseWithCode(code int, reason string) error {
+	err := ws.writeFrame(true, false, WSCloseMessage, closePayload(code, reason))
+	if cerr := ws.conn.Close(); err == nil || errors.Is(err, ErrWebSocketClosed) {
+		err = cerr
+	}
+	return err
+}
+
+// Close closes the underlying connection without a close frame.
+func (ws *WebSocketConn) Close() error {
+	return ws.conn.Close()
+}
+
+// readFrame reads a single frame and validates it against RFC 6455, Section 5.
+func (ws *WebSocketConn) readFrame() (fin, rsv1 bool, opcode int, payload []byte, err error) {
+	var header [2]byte
+	if _, err = io.ReadFull(ws.br, header[:]); err != nil {
+		return
+	}
+	fin = header[0]&wsFinBit != 0
+	rsv1 = header[0]&wsRsv1Bit != 0
+	opcode = int(header[0] & 0x0f)
+	masked := header[1]&wsMaskBit != 0
+	length := int64(header[1] & 0x7f)
+
+	switch {
+	case header[0]&(wsRsv2Bit|wsRsv3Bit) != 0, rsv1 && !ws.compress:
+		err = ws.fail(WSCloseProtocolError, "unexpected reserved bits")
+		return
+	case !masked:
+		err = ws.fail(WSCloseProtocolError, "client frames must be masked")
+		return
+	}
+
+	switch opcode {
+	case wsContinuation, WSTextMessage, WSBinaryMessage:
+	case WSCloseMessage, WSPingMessage, WSPongMessage:
+		if !fin || length > wsMaxControlPayload || rsv1 {
+			err = ws.fail(WSCloseProtocolError, "invalid control frame")
+			return
+		}
+	default:
+		err = ws.fail(WSCloseProtocolError, "unknown opcode")
+		return
+	}
+
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(ws.br, ext[:]); err != nil {
+			return
+		}
+		length = int64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(ws.br, ext[:]); err != nil {
+			return
+		}
+		length = int64(binary.BigEndian.Uint64(ext[:]))
+	}
+	if length < 0 || length > ws.readLimit {
+		err = ws.failWith(WSCloseMessageTooBig, "message too big", ErrWebSocketReadLimit)
+		return
+	}
+
+	var mask [4]byte
+	if _, err = io.ReadFull(ws.br, mask[:]); err != nil {
+		return
+	}
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(ws.br, payload); err != nil {
+		return
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return
+}
+
+func (ws *WebSocketConn) inflate(data []byte) ([]byte, error) {
+	fr := flate.NewReader(io.MultiReader(bytes.NewReader(data), bytes.NewReader(deflateTail)))
+	defer fr.Close()
+
+	out, err := io.ReadAll(io.LimitReader(fr, ws.readLimit+1))
+	if err != nil {
+		return nil, ws.fail(WSCloseInvalidFramePayloadData, "invalid compressed data")
+	}
+	if int64(len(out)) > ws.readLimit {
+		return nil, ws.failWith(WSCloseMessageTooBig, "message too big", ErrWebSocketReadLimit)
+	}
+	return out, nil
+}
+
+// handleClose answers a received close frame and returns it as *CloseError.
+func (ws *WebSocketConn) handleClose(payload []byte) error {
+	closeErr := &CloseError{Code: WSCloseNoStatusReceived}
+	switch {
+	case len(payload) == 1:
+		return ws.fail(WSCloseProtocolError, "invalid close payload")
+	case len(payload) >= 2:
+		closeErr.Code = int(binary.BigEndian.Uint16(payload))
+		closeErr.Text = string(payload[2:])
+		if !validCloseCode(closeErr.Code) {
+			return ws.fail(WSCloseProtocolError, "invalid close code")
+		}
+		if !utf8.Valid(payload[2:]) {
+			return ws.fail(WSCloseInvalidFramePayloadData, "invalid UTF-8 in close reason")
+		}
+	}
+
+	var reply []byte
+	if closeErr.Code != WSCloseNoStatusReceived {
+		reply = closePayload(closeErr.Code, "")
+	}
+	_ = ws.writeFrame(true, false, WSCloseMessage, reply)
+	ws.conn.Close()
+	return closeErr
+}
+
+// fail sends a close frame for a protocol violation of the peer and closes the connection.
+func (ws *WebSocketConn) fail(code int, reason string) error {
+	return ws.failWith(code, reason, &CloseError{Code: code, Text: reason})
+}
+
+func (ws *WebSocketConn) failWith(code int, reason string, err error) error {
+	_ = ws.writeFrame(true, false, WSCloseMessage, closePayload(code, reason))
+	ws.conn.Close()
+	return err
+}
+
+// writeFrame writes a single unmasked frame, no frame is written after a close frame.
+func (ws *WebSocketConn) writeFrame(fin, rsv1 bool, opcode int, payload []byte) error {
+	ws.frameMu.Lock()
+	defer ws.frameMu.Unlock()
+
+	if ws.closeSent {
+		return ErrWebSocketClosed
+	}
+	if opcode == WSCloseMessage {
+		ws.closeSent = true
+	}
+
+	header := make([]byte, 2, 10+len(payload))
+	header[0] = byte(opcode)
+	if fin {
+		header[0] |= wsFinBit
+	}
+	if rsv1 {
+		header[0] |= wsRsv1Bit
+	}
+	switch n := len(payload); {
+	case n <= wsMaxControlPayload:
+		header[1] = byte(n)
+	case n <= 0xffff:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+
+	_, err := ws.conn.Write(append(header, payload...))
+	return err
+}
+
+func closePayload(code int, reason string) []byte {
+	if code == WSCloseNoStatusReceived {
+		return nil
+	}
+	if len(reason) > wsMaxControlPayload-2 {
+		reason = reason[:wsMaxControlPayload-2]
+	}
+	return append(binary.BigEndian.AppendUint16(nil, uint16(code)), reason...)
+}
+
+// validCloseCode reports whether code may be sent in a close frame (RFC 6455, Section 7.4).
+func validCloseCode(code int) bool {
+	switch {
+	case code >= 1000 && code <= 1003, code >= 1007 && code <= 1011:
+		return true
+	case code >= 3000 && code <= 4999:
+		return true
+	}
+	return false
+}
+
+// wsMessageWriter sends a data message as fragments. With compression the deflate stream is
+// buffered and the last four bytes are held back, so the sync flush marker can be stripped
+// from the final fragment.
+type wsMessageWriter struct {
+	ws      *WebSocketConn
+	opcode  int
+	started bool
+	closed  bool
+	flate   *flate.Writer
+	pending bytes.Buffer
+}
+
+func (w *wsMessageWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, ErrWebSocketClosed
+	}
+	if w.flate == nil {
+		if len(p) == 0 {
+			return 0, nil
+		}
+		return len(p), w.writeFragment(false, p)
+	}
+
+	n, err := w.flate.Write(p)
+	if err != nil {
+		return n, err
+	}
+	if w.pending.Len() > wsWriteChunk+4 {
+		chunk := w.pending.Next(w.pending.Len() - 4)
+		if err := w.writeFragment(false, chunk); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+func (w *wsMessageWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	defer w.ws.msgMu.Unlock()
+
+	if w.flate == nil {
+		return w.writeFragment(true, nil)
+	}
+
+	err := w.flate.Flush()
+	flateWriterPool.Put(w.flate)
+	if err != nil {
+		return err
+	}
+	data := w.pending.Bytes()
+	if len(data) >= 4 {
+		data = data[:len(data)-4]
+	}
+	return w.writeFragment(true, data)
+}
+
+func (w *wsMessageWriter) writeFragment(fin bool, payload []byte) error {
+	opcode, rsv1 := wsContinuation, false
+	if !w.started {
+		opcode, rsv1 = w.opcode, w.flate != nil
+		w.started = true
+	}
+	return w.ws.writeFrame(fin, rsv1, opcode, payload)
+}
+
+func getFlateWriter(w io.Writer) *flate.Writer {
+	if fw, ok := flateWriterPool.Get().(*flate.Writer); ok {
+		fw.Reset(w)
+		return fw
+	}
+	fw, _ := flate.NewWriter(w, flate.DefaultCompression)
+	return fw
+}
@@ -0,0 +1,390 @@
+package dawn
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	defaultWebSocketQueueSize    = 64
+	defaultWebSocketWriteTimeout = 10 * time.Second
+)
+
+// wsAbortIndex represents a typical value used in abort functions of WSMessage.
+const wsAbortIndex int8 = math.MaxInt8 >> 1
+
+var (
+	// ErrWebSocketSlowConsumer is returned by WSClient.Send when the client's queue is full,
+	// the client is disconnected.
+	ErrWebSocketSlowConsumer = errors.New("dawn: websocket client too slow")
+
+	// ErrWebSocketHubClosed is returned by WebSocketHub.Serve after the hub was closed.
+	ErrWebSocketHubClosed = errors.New("dawn: websocket hub closed")
+)
+
+// WSHandlerFunc defines the handler of a received WebSocket message, used as middleware too.
+type WSHandlerFunc func(m *WSMessage)
+
+// WSMessage is a message received from a WSClient as it passes through the handlers chain.
+type WSMessage struct {
+	Client *WSClient
+	Type   int
+	Data   []byte
+
+	handlers []WSHandlerFunc
+	index    int8
+}
+
+// Next executes the pending handlers in the chain inside the calling handler.
+func (m *WSMessage) Next() {
+	m.index++
+	for m.index < int8(len(m.handlers)) {
+		m.handlers[m.index](m)
+		m.index++
+	}
+}
+
+// Abort prevents pending handlers from being called for this message.
+func (m *WSMessage) Abort() {
+	m.index = wsAbortIndex
+}
+
+// IsAborted returns true if the message was aborted.
+func (m *WSMessage) IsAborted() bool {
+	return m.index >= wsAbortIndex
+}
+
+// WebSocketHubConfig defines the config for WebSocketHub.
+type WebSocketHubConfig struct {
+	// Upgrade are the options of the WebSocket handshake.
+	Upgrade WebSocketOptions
+	// QueueSize is the number of outgoing messages queued per client, defaults to 64. A client
+	// whose queue is full is disconnected.
+	QueueSize int
+	// WriteTimeout bounds the write of a single message, defaults to 10 seconds.
+	WriteTimeout time.Duration
+	// PingInterval is the interval of keep-alive pings, zero disables them.
+	PingInterval time.Duration
+	// OnConnect is called when a client connected, before its messages are read.
+	OnConnect func(client *WSClient)
+	// OnDisconnect is called when a client disconnected, after it left its rooms.
+	OnDisconnect func(client *WSClient)
+}
+
+// WebSocketHub manages WebSocket clients and the rooms they joined.
+type WebSocketHub struct {
+	config     WebSocketHubConfig
+	middleware []WSHandlerFunc
+
+	mu      sync.RWMutex
+	closed  bool
+	clients map[*WSClient]struct{}
+	rooms   map[string]map[*WSClient]struct{}
+}
+
+// NewWebSocketHub returns a WebSocketHub whose clients are disconnected when the engine shuts down.
+func (e *Engine) NewWebSocketHub(config WebSocketHubConfig) *WebSocketHub {
+	if config.QueueSize <= 0 {
+		config.QueueSize = defaultWebSocketQueueSize
+	}
+	if config.WriteTimeout <= 0 {
+		config.WriteTimeout = defaultWebSocketWriteTimeout
+	}
+	h := &WebSocketHub{
+		config:  config,
+		clients: make(map[*WSClient]struct{}),
+		rooms:   make(map[string]map[*WSClient]struct{}),
+	}
+	e.RegisterOnShutdown(h.Close)
+	return h
+}
+
+// Use attaches middleware to the hub, it runs for every message before the handlers given
+// to Serve, e.g. to re-check authorization, limit rates or log.
+func (h *WebSocketHub) Use(middleware ...WSHandlerFunc) {
+	h.middleware = append(h.middleware, middleware...)
+}
+
+// Handler returns a HandlerFunc which serves the request with Serve.
+func (h *WebSocketHub) Handler(handlers ...WSHandlerFunc) HandlerFunc {
+	return func(c *Context) {
+		if err := h.Serve(c, handlers...); err != nil {
+			_ = c.Error(err)
+		}
+	}
+}
+
+// Serve upgrades the request and passes every message of the client through the hub
+// middleware and handlers. The client carries a copy of c.Keys, e.g. the authenticated
+// identity. It blocks until the client disconnects.
+func (h *WebSocketHub) Serve(c *Context, handlers ...WSHandlerFunc) error {
+	h.mu.RLock()
+	closed := h.closed
+	h.mu.RUnlock()
+	if closed {
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return ErrWebSocketHubClosed
+	}
+
+	conn, err := c.UpgradeWebSocket(h.config.Upgrade)
+	if err != nil {
+		return err
+	}
+
+	client := &WSClient{
+		Conn:  conn,
+		hub:   h,
+		keys:  c.Copy().Keys,
+		rooms: make(map[string]struct{}),
+		send:  make(chan wsOutgoing, h.config.QueueSize),
+		done:  make(chan struct{}),
+	}
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return conn.CloseWithCode(WSCloseGoingAway, "server shutting down")
+	}
+	h.clients[client] = struct{}{}
+	h.mu.Unlock()
+
+	writerDone := make(chan struct{})
+	go func() {
+		client.writeLoop()
+		close(writerDone)
+	}()
+
+	if h.config.OnConnect != nil {
+		h.config.OnConnect(client)
+	}
+
+	chain := make([]WSHandlerFunc, 0, len(h.middleware)+len(handlers))
+	chain = append(chain, h.middleware...)
+	chain = append(chain, handlers...)
+	for {
+		messageType, data, err := conn.ReadMessage()
+		if err != nil {
+			break
+		}
+		m := &WSMessage{Client: client, Type: messageType, Data: data, handlers: chain, index: -1}
+		m.Next()
+	}
+
+	client.CloseWithCode(WSCloseNormalClosure, "")
+	<-writerDone
+	h.remove(client)
+	if h.config.OnDisconnect != nil {
+		h.config.OnDisconnect(client)
+	}
+	return nil
+}
+
+// Broadcast sends the message to every client in room.
+func (h *WebSocketHub) Broadcast(room string, messageType int, data []byte) {
+	h.broadcast(room, nil, messageType, data)
+}
+
+// Clients returns the number of clients in room.
+func (h *WebSocketHub) Clients(room string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.rooms[room])
+}
+
+// Close disconnects all the clients, later calls to Serve fail.
+func (h *WebSocketHub) Close() {
+	h.mu.Lock()
+	h.closed = true
+	clients := make([]*WSClient, 0, len(h.clients))
+	for client := range h.clients {
+		clients = append(clients, client)
+	}
+	h.mu.Unlock()
+
+	for _, client := range clients {
+		client.CloseWithCode(WSCloseGoingAway, "server shutting down")
+	}
+}
+
+func (h *WebSocketHub) broadcast(room string, except *WSClient, messageType int, data []byte) {
+	h.mu.RLock()
+	clients := make([]*WSClient, 0, len(h.rooms[room]))
+	for client := range h.rooms[room] {
+		if client != except {
+			clients = append(clients, client)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, client := range clients {
+		_ = client.Send(messageType, data)
+	}
+}
+
+func (h *WebSocketHub) remove(client *WSClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.clients, client)
+	for room := range client.rooms {
+		h.leave(client, room)
+	}
+}
+
+// leave removes client from room, h.mu must be held.
+func (h *WebSocketHub) leave(client *WSClient, room string) {
+	delete(client.rooms, room)
+	if clients := h.rooms[room]; clients != nil {
+		delete(clients, client)
+		if len(clients) == 0 {
+			delete(h.rooms, room)
+		}
+	}
+}
+
+// WSClient is a connection served by a WebSocketHub. Messages sent to it are queued and
+// written by its own goroutine.
+type WSClient struct {
+	// Conn is the underlying connection, use Send instead of writing to it directly.
+	Conn *WebSocketConn
+
+	hub  *WebSocketHub
+	mu   sync.RWMutex
+	keys map[string]any
+	// rooms is protected by hub.mu.
+	rooms map[string]struct{}
+
+	send      chan wsOutgoing
+	done      chan struct{}
+	closeOnce sync.Once
+	closeCode int
+	closeText string
+}
+
+type wsOutgoing struct {
+	messageType int
+	data        []byte
+}
+
+// Get returns the value for the given key, copied from the upgrading Context.Keys or set later.
+func (cl *WSClient) Get(key string) (value any, exists bool) {
+	cl.mu.RLock()
+	defer cl.mu.RUnlock()
+	value, exists = cl.keys[key]
+	return
+}
+
+// Set stores a new key/value pair for this client.
+func (cl *WSClient) Set(key string, value any) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	if cl.keys == nil {
+		cl.keys = make(map[string]any)
+	}
+	cl.keys[key] = value
+}
+
+// Join adds the client to room.
+func (cl *WSClient) Join(room string) {
+	h := cl.hub
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.clients[cl]; !ok {
+		return
+	}
+	clients, ok := h.rooms[room]
+	if !ok {
+		clients = make(map[*WSClient]struct{})
+		h.rooms[room] = clients
+	}
+	clients[cl] = struct{}{}
+	cl.rooms[room] = struct{}{}
+}
+
+// Leave removes the client from room.
+func (cl *WSClient) Leave(room string) {
+	cl.hub.mu.Lock()
+	cl.hub.leave(cl, room)
+	cl.hub.mu.Unlock()
+}
+
+// Rooms returns the rooms the client joined.
+func (cl *WSClient) Rooms() []string {
+	cl.hub.mu.RLock()
+	defer cl.hub.mu.RUnlock()
+
+	rooms := make([]string, 0, len(cl.rooms))
+	for room := range cl.rooms {
+		rooms = append(rooms, room)
+	}
+	return rooms
+}
+
+// Send queues the message for the client without blocking. If the queue is full the client
+// is disconnected and ErrWebSocketSlowConsumer is returned.
+func (cl *WSClient) Send(messageType int, data []byte) error {
+	select {
+	case <-cl.done:
+		return ErrWebSocketClosed
+	default:
+	}
+
+	select {
+	case cl.send <- wsOutgoing{messageType: messageType, data: data}:
+		return nil
+	default:
+		cl.CloseWithCode(WSClosePolicyViolation, "slow consumer")
+		return ErrWebSocketSlowConsumer
+	}
+}
+
+// Broadcast sends the message to the other clients in room.
+func (cl *WSClient) Broadcast(room string, messageType int, data []byte) {
+	cl.hub.broadcast(room, cl, messageType, data)
+}
+
+// CloseWithCode disconnects the client with the given close code once the message being
+// written, if any, is sent.
+func (cl *WSClient) CloseWithCode(code int, reason string) {
+	cl.closeOnce.Do(func() {
+		cl.closeCode, cl.closeText = code, reason
+		close(cl.done)
+	})
+}
+
+func (cl *WSClient) writeLoop() {
+	var ping <-chan time.Time
+	if interval := cl.hub.config.PingInterval; interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		ping = ticker.C
+	}
+
+	timeout := cl.hub.config.WriteTimeout
+	for {
+		var err error
+		select {
+		case <-cl.done:
+			if cl.closeCode == WSCloseAbnormalClosure {
+				_ = cl.Conn.Close()
+				return
+			}
+			_ = cl.Conn.SetWriteDeadline(time.Now().Add(timeout))
+			_ = cl.Conn.CloseWithCode(cl.closeCode, cl.closeText)
+			return
+		case msg := <-cl.send:
+			_ = cl.Conn.SetWriteDeadline(time.Now().Add(timeout))
+			err = cl.Conn.WriteMessage(msg.messageType, msg.data)
+		case <-ping:
+			_ = cl.Conn.SetWriteDeadline(time.Now().Add(timeout))
+			err = cl.Conn.Ping(nil)
+		}
+		if err != nil { // the connection is broken, no close frame can be sent
+			cl.CloseWithCode(WSCloseAbnormalClosure, "")
+		}
+	}
+}
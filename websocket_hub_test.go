@@ -0,0 +1,146 @@
+package dawn
+
+import (
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// webSocketHubServer serves h with handlers, the "user" query parameter is set in Context.Keys.
+func webSocketHubServer(t *testing.T, e *Engine, h *WebSocketHub, handlers ...WSHandlerFunc) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		c := &Context{Request: req, engine: e, index: -1}
+		c.writermem.reset(w)
+		c.Writer = &c.writermem
+		c.Set("user", req.URL.Query().Get("user"))
+		_ = h.Serve(c, handlers...)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func dialWebSocketHub(t *testing.T, srv *httptest.Server, user string) *wsTestClient {
+	cl, resp := dialWebSocket(t, srv.URL+"/?user="+user, nil)
+	require.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	return cl
+}
+
+func waitClients(t *testing.T, h *WebSocketHub, room string, n int) {
+	require.Eventually(t, func() bool {
+		return h.Clients(room) == n
+	}, time.Second, time.Millisecond, "%d clients in %s", n, room)
+}
+
+func TestWebSocketHubBroadcast(t *testing.T) {
+	e := New()
+	var disconnected atomic.Int32
+	h := e.NewWebSocketHub(WebSocketHubConfig{
+		OnConnect:    func(client *WSClient) { client.Join("lobby") },
+		OnDisconnect: func(client *WSClient) { disconnected.Add(1) },
+	})
+	h.Use(func(m *WSMessage) {
+		if user, _ := m.Client.Get("user"); user != "admin" && string(m.Data) == "/shutdown" {
+			m.Abort()
+			return
+		}
+		m.Next()
+	})
+	srv := webSocketHubServer(t, e, h, func(m *WSMessage) {
+		m.Client.Broadcast("lobby", m.Type, m.Data)
+	})
+
+	admin := dialWebSocketHub(t, srv, "admin")
+	gopher := dialWebSocketHub(t, srv, "gopher")
+	waitClients(t, h, "lobby", 2)
+
+	gopher.writeFrame(t, true, false, WSTextMessage, []byte("/shutdown"), true)
+	gopher.writeFrame(t, true, false, WSTextMessage, []byte("hello"), true)
+	opcode, data := admin.readMessage(t)
+	assert.Equal(t, WSTextMessage, opcode)
+	assert.Equal(t, "hello", string(data))
+
+	h.Broadcast("lobby", WSBinaryMessage, []byte{42})
+	for _, cl := range []*wsTestClient{admin, gopher} {
+		opcode, data = cl.readMessage(t)
+		assert.Equal(t, WSBinaryMessage, opcode)
+		assert.Equal(t, []byte{42}, data)
+	}
+
+	gopher.writeFrame(t, true, false, WSCloseMessage, closePayload(WSCloseNormalClosure, ""), true)
+	opcode, _ = gopher.readMessage(t)
+	assert.Equal(t, WSCloseMessage, opcode)
+	waitClients(t, h, "lobby", 1)
+	require.Eventually(t, func() bool { return disconnected.Load() == 1 }, time.Second, time.Millisecond)
+}
+
+func TestWebSocketHubRooms(t *testing.T) {
+	e := New()
+	h := e.NewWebSocketHub(WebSocketHubConfig{})
+	srv := webSocketHubServer(t, e, h, func(m *WSMessage) {
+		switch string(m.Data) {
+		case "join":
+			m.Client.Join("room")
+		case "leave":
+			m.Client.Leave("room")
+		}
+		_ = m.Client.Send(WSTextMessage, []byte(m.Data))
+	})
+
+	cl := dialWebSocketHub(t, srv, "gopher")
+	cl.writeFrame(t, true, false, WSTextMessage, []byte("join"), true)
+	_, data := cl.readMessage(t)
+	assert.Equal(t, "join", string(data))
+	assert.Equal(t, 1, h.Clients("room"))
+
+	cl.writeFrame(t, true, false, WSTextMessage, []byte("leave"), true)
+	_, data = cl.readMessage(t)
+	assert.Equal(t, "leave", string(data))
+	assert.Equal(t, 0, h.Clients("room"))
+}
+
+func TestWebSocketHubSlowConsumer(t *testing.T) {
+	e := New()
+	h := e.NewWebSocketHub(WebSocketHubConfig{
+		QueueSize:    1,
+		WriteTimeout: 50 * time.Millisecond,
+		OnConnect:    func(client *WSClient) { client.Join("lobby") },
+	})
+	srv := webSocketHubServer(t, e, h)
+	dialWebSocketHub(t, srv, "gopher")
+	waitClients(t, h, "lobby", 1)
+
+	// the client never reads, so its queue fills up once the connection buffers are full.
+	payload := make([]byte, 64<<10)
+	require.Eventually(t, func() bool {
+		h.Broadcast("lobby", WSBinaryMessage, payload)
+		return h.Clients("lobby") == 0
+	}, 5*time.Second, time.Millisecond)
+}
+
+func TestWebSocketHubClose(t *testing.T) {
+	e := New()
+	h := e.NewWebSocketHub(WebSocketHubConfig{
+		OnConnect: func(client *WSClient) { client.Join("lobby") },
+	})
+	srv := webSocketHubServer(t, e, h)
+	cl := dialWebSocketHub(t, srv, "gopher")
+	waitClients(t, h, "lobby", 1)
+
+	e.Shutdown()
+	opcode, data := cl.readMessage(t)
+	assert.Equal(t, WSCloseMessage, opcode)
+	require.GreaterOrEqual(t, len(data), 2)
+	assert.Equal(t, WSCloseGoingAway, int(binary.BigEndian.Uint16(data)))
+	waitClients(t, h, "lobby", 0)
+
+	c, w := createTestContext(httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.ErrorIs(t, h.Serve(c), ErrWebSocketHubClosed)
+	c.Writer.WriteHeaderNow()
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
@@ -0,0 +1,308 @@
+package dawn
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// webSocketServer serves UpgradeWebSocket with opts and passes the connections to handler.
+func webSocketServer(t *testing.T, opts WebSocketOptions, handler func(c *Context, ws *WebSocketConn)) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		c := &Context{Request: req, engine: New(), index: -1}
+		c.writermem.reset(w)
+		c.Writer = &c.writermem
+		c.SetCookie("user", "gopher", 0, "/", "", false, true)
+		ws, err := c.UpgradeWebSocket(opts)
+		if err != nil {
+			c.Writer.WriteHeaderNow()
+			return
+		}
+		defer ws.Close()
+		handler(c, ws)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// wsTestClient is a minimal client side of a WebSocket connection, it masks its frames.
+type wsTestClient struct {
+	conn net.Conn
+	br   *bufio.Reader
+}
+
+const wsTestKey = "dGhlIHNhbXBsZSBub25jZQ=="
+
+func dialWebSocket(t *testing.T, url string, header http.Header) (*wsTestClient, *http.Response) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	conn, err := net.Dial("tcp", req.URL.Host)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", wsTestKey)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	for k, vs := range header {
+		req.Header[k] = vs
+	}
+	require.NoError(t, req.Write(conn))
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	require.NoError(t, err)
+	return &wsTestClient{conn: conn, br: br}, resp
+}
+
+func (cl *wsTestClient) writeFrame(t *testing.T, fin, rsv1 bool, opcode int, payload []byte, masked bool) {
+	header := []byte{byte(opcode), byte(len(payload))}
+	if fin {
+		header[0] |= wsFinBit
+	}
+	if rsv1 {
+		header[0] |= wsRsv1Bit
+	}
+	if len(payload) > wsMaxControlPayload {
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(len(payload)))
+	}
+	data := append([]byte(nil), payload...)
+	if masked {
+		header[1] |= wsMaskBit
+		mask := []byte{1, 2, 3, 4}
+		header = append(header, mask...)
+		for i := range data {
+			data[i] ^= mask[i%4]
+		}
+	}
+	_, err := cl.conn.Write(append(header, data...))
+	require.NoError(t, err)
+}
+
+func (cl *wsTestClient) readFrame(t *testing.T) (fin, rsv1 bool, opcode int, payload []byte) {
+	var header [2]byte
+	_, err := io.ReadFull(cl.br, header[:])
+	require.NoError(t, err)
+	require.Zero(t, header[1]&wsMaskBit, "server frames must not be masked")
+
+	length := uint64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		var ext [2]byte
+		_, err = io.ReadFull(cl.br, ext[:])
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		_, err = io.ReadFull(cl.br, ext[:])
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	require.NoError(t, err)
+	payload = make([]byte, length)
+	_, err = io.ReadFull(cl.br, payload)
+	require.NoError(t, err)
+	return header[0]&wsFinBit != 0, header[0]&wsRsv1Bit != 0, int(header[0] & 0x0f), payload
+}
+
+// readMessage returns the next data or close message, reassembled and decompressed.
+func (cl *wsTestClient) readMessage(t *testing.T) (opcode int, data []byte) {
+	var compressed bool
+	for {
+		fin, rsv1, op, payload := cl.readFrame(t)
+		if op != wsContinuation {
+			opcode, compressed = op, rsv1
+		}
+		data = append(data, payload...)
+		if fin {
+			break
+		}
+	}
+	if compressed {
+		fr := flate.NewReader(io.MultiReader(bytes.NewReader(data), bytes.NewReader(deflateTail)))
+		var err error
+		data, err = io.ReadAll(fr)
+		require.NoError(t, err)
+	}
+	return opcode, data
+}
+
+func echoWebSocket(_ *Context, ws *WebSocketConn) {
+	for {
+		messageType, data, err := ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		if err := ws.WriteMessage(messageType, data); err != nil {
+			return
+		}
+	}
+}
+
+func TestAcceptsPerMessageDeflate(t *testing.T) {
+	tests := []struct {
+		header string
+		ok     bool
+	}{
+		{"permessage-deflate", true},
+		{"permessage-deflate; client_max_window_bits", true},
+		{"x-webkit-deflate-frame, permessage-deflate; server_no_context_takeover", true},
+		{`permessage-deflate; server_max_window_bits="15"`, true},
+		{"permessage-deflate; server_max_window_bits=10", false},
+		{"permessage-deflate; unknown", false},
+		{"permessage-deflate; unknown; server_max_window_bits=15", false},
+		{"permessage-deflate; server_max_window_bits=10, permessage-deflate", true},
+		{"x-webkit-deflate-frame", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.ok, acceptsPerMessageDeflate([]string{tt.header}), tt.header)
+	}
+}
+
+func TestUpgradeWebSocketRejected(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		header map[string]string
+		status int
+		err    error
+	}{
+		{"method", http.MethodPost, nil, http.StatusBadRequest, ErrWebSocketHandshake},
+		{"upgrade", http.MethodGet, map[string]string{"Upgrade": ""}, http.StatusBadRequest, ErrWebSocketHandshake},
+		{"version", http.MethodGet, map[string]string{"Sec-WebSocket-Version": "8"}, http.StatusUpgradeRequired, ErrWebSocketHandshake},
+		{"key", http.MethodGet, map[string]string{"Sec-WebSocket-Key": "c2hvcnQ="}, http.StatusBadRequest, ErrWebSocketHandshake},
+		{"origin", http.MethodGet, map[string]string{"Origin": "https://evil.example"}, http.StatusForbidden, ErrWebSocketOrigin},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "http://example.com/ws", nil)
+		req.Header.Set("Upgrade", "websocket")
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Sec-WebSocket-Key", wsTestKey)
+		req.Header.Set("Sec-WebSocket-Version", "13")
+		for k, v := range tt.header {
+			req.Header.Set(k, v)
+		}
+		c, w := createTestContext(req)
+		ws, err := c.UpgradeWebSocket(WebSocketOptions{AllowedOrigins: []string{"https://example.org"}})
+		c.Writer.WriteHeaderNow()
+		assert.Nil(t, ws, tt.name)
+		assert.ErrorIs(t, err, tt.err, tt.name)
+		assert.Equal(t, tt.status, w.Code, tt.name)
+		assert.True(t, c.IsAborted(), tt.name)
+	}
+}
+
+func TestWebSocketHandshake(t *testing.T) {
+	srv := webSocketServer(t, WebSocketOptions{Subprotocols: []string{"chat", "json"}}, echoWebSocket)
+	_, resp := dialWebSocket(t, srv.URL, http.Header{"Sec-Websocket-Protocol": {"json, chat"}})
+
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	assert.Equal(t, "websocket", resp.Header.Get("Upgrade"))
+	// the example of RFC 6455, Section 1.3.
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", resp.Header.Get("Sec-WebSocket-Accept"))
+	assert.Equal(t, "chat", resp.Header.Get("Sec-WebSocket-Protocol"))
+	assert.Empty(t, resp.Header.Get("Sec-WebSocket-Extensions"))
+	assert.Contains(t, resp.Header.Get("Set-Cookie"), "user=gopher")
+}
+
+func TestWebSocketMessages(t *testing.T) {
+	srv := webSocketServer(t, WebSocketOptions{}, echoWebSocket)
+	cl, _ := dialWebSocket(t, srv.URL, nil)
+
+	cl.writeFrame(t, true, false, WSTextMessage, []byte("hello"), true)
+	opcode, data := cl.readMessage(t)
+	assert.Equal(t, WSTextMessage, opcode)
+	assert.Equal(t, "hello", string(data))
+
+	// a ping between the fragments of a message is answered first.
+	cl.writeFrame(t, false, false, WSBinaryMessage, []byte{1, 2}, true)
+	cl.writeFrame(t, true, false, WSPingMessage, []byte("ping"), true)
+	cl.writeFrame(t, true, false, wsContinuation, bytes.Repeat([]byte{3}, 300), true)
+	opcode, data = cl.readMessage(t)
+	assert.Equal(t, WSPongMessage, opcode)
+	assert.Equal(t, "ping", string(data))
+	opcode, data = cl.readMessage(t)
+	assert.Equal(t, WSBinaryMessage, opcode)
+	assert.Equal(t, append([]byte{1, 2}, bytes.Repeat([]byte{3}, 300)...), data)
+
+	cl.writeFrame(t, true, false, WSCloseMessage, closePayload(WSCloseGoingAway, "bye"), true)
+	opcode, data = cl.readMessage(t)
+	assert.Equal(t, WSCloseMessage, opcode)
+	assert.Equal(t, closePayload(WSCloseGoingAway, ""), data)
+}
+
+func TestWebSocketCompression(t *testing.T) {
+	srv := webSocketServer(t, WebSocketOptions{EnableCompression: true}, func(_ *Context, ws *WebSocketConn) {
+		assert.True(t, ws.Compressed())
+		echoWebSocket(nil, ws)
+	})
+	cl, resp := dialWebSocket(t, srv.URL, http.Header{
+		"Sec-Websocket-Extensions": {"permessage-deflate; unknown; server_max_window_bits=15, permessage-deflate; client_max_window_bits"},
+	})
+	assert.Contains(t, resp.Header.Get("Sec-WebSocket-Extensions"), "permessage-deflate")
+
+	message := strings.Repeat("compress me ", 2000)
+	var compressed bytes.Buffer
+	fw, _ := flate.NewWriter(&compressed, flate.BestSpeed)
+	_, _ = fw.Write([]byte(message))
+	require.NoError(t, fw.Flush())
+	cl.writeFrame(t, true, true, WSTextMessage, bytes.TrimSuffix(compressed.Bytes(), []byte{0, 0, 0xff, 0xff}), true)
+
+	opcode, data := cl.readMessage(t)
+	assert.Equal(t, WSTextMessage, opcode)
+	assert.Equal(t, message, string(data))
+}
+
+func TestWebSocketProtocolErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		send func(cl *wsTestClient)
+		code int
+	}{
+		{"unmasked", func(cl *wsTestClient) {
+			cl.writeFrame(t, true, false, WSTextMessage, []byte("hello"), false)
+		}, WSCloseProtocolError},
+		{"reserved bit", func(cl *wsTestClient) {
+			cl.writeFrame(t, true, true, WSTextMessage, []byte("hello"), true)
+		}, WSCloseProtocolError},
+		{"continuation", func(cl *wsTestClient) {
+			cl.writeFrame(t, true, false, wsContinuation, []byte("hello"), true)
+		}, WSCloseProtocolError},
+		{"utf-8", func(cl *wsTestClient) {
+			cl.writeFrame(t, true, false, WSTextMessage, []byte{0xff, 0xfe}, true)
+		}, WSCloseInvalidFramePayloadData},
+		{"read limit", func(cl *wsTestClient) {
+			cl.writeFrame(t, true, false, WSBinaryMessage, make([]byte, 200), true)
+		}, WSCloseMessageTooBig},
+		{"fragmented read limit", func(cl *wsTestClient) {
+			cl.writeFrame(t, false, false, WSBinaryMessage, make([]byte, 100), true)
+			cl.writeFrame(t, true, false, wsContinuation, make([]byte, 100), true)
+		}, WSCloseMessageTooBig},
+	}
+	for _, tt := range tests {
+		errs := make(chan error, 1)
+		srv := webSocketServer(t, WebSocketOptions{ReadLimit: 128}, func(_ *Context, ws *WebSocketConn) {
+			_, _, err := ws.ReadMessage()
+			errs <- err
+		})
+		cl, _ := dialWebSocket(t, srv.URL, nil)
+		tt.send(cl)
+
+		opcode, data := cl.readMessage(t)
+		assert.Equal(t, WSCloseMessage, opcode, tt.name)
+		require.GreaterOrEqual(t, len(data), 2, tt.name)
+		assert.Equal(t, tt.code, int(binary.BigEndian.Uint16(data)), tt.name)
+		assert.Error(t, <-errs, tt.name)
+	}
+}